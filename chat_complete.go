@@ -1,16 +1,17 @@
 package google
 
 import (
+	"bytes"
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
-	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
@@ -23,39 +24,194 @@ import (
 type ChatCompleteModel string
 
 const (
-	ChatCompleteModelGemini2_0Flash = ChatCompleteModel("models/gemini-2.0-flash")
-	ChatCompleteModelGemini2_5Flash = ChatCompleteModel("models/gemini-2.5-flash")
-	ChatCompleteModelGemini2_5Pro   = ChatCompleteModel("models/gemini-2.5-pro")
+	ChatCompleteModelGemini2_0Flash             = ChatCompleteModel("models/gemini-2.0-flash")
+	ChatCompleteModelGemini2_0FlashLite         = ChatCompleteModel("models/gemini-2.0-flash-lite")
+	ChatCompleteModelGemini2_5Flash             = ChatCompleteModel("models/gemini-2.5-flash")
+	ChatCompleteModelGemini2_5FlashImage        = ChatCompleteModel("models/gemini-2.5-flash-image")
+	ChatCompleteModelGemini2_5FlashImagePreview = ChatCompleteModel("models/gemini-2.5-flash-image-preview")
+	ChatCompleteModelGemini2_5FlashLite         = ChatCompleteModel("models/gemini-2.5-flash-lite")
+	ChatCompleteModelGemini2_5Pro               = ChatCompleteModel("models/gemini-2.5-pro")
 )
 
 type ChatCompleter struct {
-	Client *genai.Client
-	log    *slog.Logger
-	model  ChatCompleteModel
-	tracer trace.Tracer
+	Client                *genai.Client
+	codeExecution         bool
+	content               contentPolicy
+	idGenerator           IDGenerator
+	log                   *slog.Logger
+	messageEvents         bool
+	metrics               *metrics
+	model                 ChatCompleteModel
+	modelCatalog          *ModelCatalog
+	multimodalToolResults bool
+	pricing               *ModelPricing
+	responseModalities    []genai.Modality
+	spanAttributeMode     SpanAttributeMode
+	streamToolCallArgs    bool
+	toolResultEncoder     ToolResultEncoder
+	tracer                trace.Tracer
+	urlContext            bool
 }
 
 type NewChatCompleterOptions struct {
+	// CodeExecution enables the built-in code execution tool.
+	// Generated code and its results are returned as parts of type [MessagePartTypeExecutableCode]
+	// and [MessagePartTypeCodeExecutionResult].
+	CodeExecution bool
+
+	// IDGenerator for tool call IDs, used when the model doesn't provide them.
+	// Defaults to [RandomID].
+	IDGenerator IDGenerator
+
+	// Model defaults to the default model of [ModelFamilyChat], see [ModelSpecs].
 	Model ChatCompleteModel
+
+	// ModelCatalog to validate requests against before sending them, for example rejecting image output
+	// on a model that only outputs text. Errors from validation wrap [ErrUnsupportedByModel].
+	// If nil, or if the catalog lookup fails, requests for models in the table of known models are validated
+	// against their [ModelSpec].
+	ModelCatalog *ModelCatalog
+
+	// MultimodalToolResults attaches data parts directly following a tool result part in the same message
+	// to the function response, for example images returned by a tool.
+	// Otherwise, they're sent as regular data parts.
+	MultimodalToolResults bool
+
+	// Pricing overrides the default prices of the model used for [ChatCompleteResponseMetadata.Cost],
+	// for example with negotiated prices. See [LookupPricing] for the defaults.
+	Pricing *ModelPricing
+
+	// ResponseModalities the model should respond with, for example [genai.ModalityText] and [genai.ModalityImage]
+	// for image generation with [ChatCompleteModelGemini2_5FlashImage].
+	// Generated images are returned as parts of type [gai.MessagePartTypeData], with a [*bytes.Reader] as the data,
+	// so it can be rewound before sending the image back to the model in a later request.
+	// Defaults to the model default, usually text only.
+	ResponseModalities []genai.Modality
+
+	// StreamToolCallArgs streams tool call arguments as they are generated, as parts of type
+	// [MessagePartTypeToolCallDelta], followed by the complete tool call part.
+	// This is only supported on Vertex AI, see [NewClientOptions.Project].
+	// With the Gemini API, the option is ignored and only complete tool call parts are returned.
+	// If the stream ends or another tool call starts before the arguments are complete, the stream returns an error.
+	StreamToolCallArgs bool
+
+	// ToolResultEncoder encodes tool results into function responses.
+	// Defaults to [EncodeToolResultAsJSON].
+	ToolResultEncoder ToolResultEncoder
+
+	// URLContext enables the built-in URL context tool, so the model can read pages referenced by URL in the prompt.
+	// The retrieved URLs are available in [ChatCompleteResponseMetadata.URLContext].
+	URLContext bool
 }
 
 func (c *Client) NewChatCompleter(opts NewChatCompleterOptions) *ChatCompleter {
+	if opts.Model == "" {
+		opts.Model = defaultModel[ChatCompleteModel](ModelFamilyChat)
+	}
+	if opts.IDGenerator == nil {
+		opts.IDGenerator = RandomID
+	}
+	if opts.ToolResultEncoder == nil {
+		opts.ToolResultEncoder = EncodeToolResultAsJSON
+	}
+	if opts.Pricing == nil {
+		if p, ok := LookupPricing(opts.Model); ok {
+			opts.Pricing = &p
+		}
+	}
+
 	return &ChatCompleter{
-		Client: c.Client,
-		log:    c.log,
-		model:  opts.Model,
-		tracer: otel.Tracer("maragu.dev/gai-google"),
+		Client:                c.Client,
+		codeExecution:         opts.CodeExecution,
+		content:               c.content,
+		idGenerator:           opts.IDGenerator,
+		log:                   c.log,
+		messageEvents:         c.messageEvents,
+		metrics:               c.metrics,
+		model:                 opts.Model,
+		modelCatalog:          opts.ModelCatalog,
+		multimodalToolResults: opts.MultimodalToolResults,
+		pricing:               opts.Pricing,
+		responseModalities:    opts.ResponseModalities,
+		spanAttributeMode:     c.spanAttributeMode,
+		streamToolCallArgs:    opts.StreamToolCallArgs && isVertexAI(c.Client),
+		toolResultEncoder:     opts.ToolResultEncoder,
+		tracer:                c.tracer,
+		urlContext:            opts.URLContext,
 	}
 }
 
+// ChatCompleteRequest is a [gai.ChatCompleteRequest] with Gemini-specific options.
+type ChatCompleteRequest struct {
+	gai.ChatCompleteRequest
+
+	// FunctionCalling controls how the model calls the tools in [gai.ChatCompleteRequest.Tools],
+	// for example forcing a tool call with [genai.FunctionCallingConfigModeAny].
+	// AllowedFunctionNames must be names of tools in the request, and can only be set with
+	// [genai.FunctionCallingConfigModeAny] or [genai.FunctionCallingConfigModeValidated].
+	FunctionCalling *genai.FunctionCallingConfig
+}
+
+// ChatCompleteResponse is a [gai.ChatCompleteResponse] with Gemini-specific metadata.
+// Like [gai.ChatCompleteResponse.Meta], the [ChatCompleteResponse.GoogleMeta] field is updated continuously
+// until the streaming response is complete.
+type ChatCompleteResponse struct {
+	gai.ChatCompleteResponse
+	GoogleMeta *ChatCompleteResponseMetadata
+}
+
+// ChatCompleteResponseMetadata contains Gemini-specific metadata about the response.
+type ChatCompleteResponseMetadata struct {
+	// Cost is estimated from the token usage and the model pricing, see [NewChatCompleterOptions.Pricing].
+	// It's nil if there's no pricing for the model.
+	Cost *CostEstimate
+
+	// TimeToFirstToken is the time from the start of the request to the first content part in the response,
+	// like text or a tool call. It's zero until then.
+	TimeToFirstToken time.Duration
+
+	// Usage broken down by modality, with cached and tool use tokens.
+	Usage UsageDetails
+
+	// ToolCallIDs of the tool calls in the response, in order.
+	// The tool calls in a response all belong to the same model turn (parallel function calling),
+	// so their results should be sent back together in one message.
+	ToolCallIDs []string
+
+	// URLContext has the URLs retrieved by the URL context tool, if enabled.
+	URLContext []URLMetadata
+}
+
+// URLMetadata about a URL retrieved by the URL context tool.
+type URLMetadata struct {
+	URL    string
+	Status genai.URLRetrievalStatus
+}
+
+// ChatComplete satisfies [gai.ChatCompleter].
+// Use [ChatCompleter.ChatCompleteGoogle] for Gemini-specific options and metadata.
 func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
-	ctx, span := c.tracer.Start(ctx, "google.chat_complete",
+	res, err := c.ChatCompleteGoogle(ctx, ChatCompleteRequest{ChatCompleteRequest: req})
+	if err != nil {
+		return gai.ChatCompleteResponse{}, err
+	}
+	return res.ChatCompleteResponse, nil
+}
+
+// ChatCompleteGoogle is like [ChatCompleter.ChatComplete], but with Gemini-specific options and metadata.
+func (c *ChatCompleter) ChatCompleteGoogle(ctx context.Context, req ChatCompleteRequest) (_ ChatCompleteResponse, err error) {
+	genAIAttrs := genAIAttributes(c.Client, "chat", string(c.model))
+	ctx, span := c.tracer.Start(ctx, c.spanAttributeMode.spanName("google.chat_complete", "chat", string(c.model)),
 		trace.WithSpanKind(trace.SpanKindClient),
-		trace.WithAttributes(
-			attribute.String("ai.model", string(c.model)),
-			attribute.Int("ai.message_count", len(req.Messages)),
-		),
+		trace.WithAttributes(c.spanAttributeMode.attributes(
+			[]attribute.KeyValue{
+				attribute.String("ai.model", string(c.model)),
+				attribute.Int("ai.message_count", len(req.Messages)),
+			},
+			genAIAttrs,
+		)...),
 	)
+	sa := spanAttributes{mode: c.spanAttributeMode, span: span}
 
 	if len(req.Messages) == 0 {
 		panic("no messages")
@@ -65,19 +221,60 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		panic("last message must have user role")
 	}
 
+	log := logWithTrace(ctx, c.log).With("model", c.model)
+	log.Debug("Constructing chat completion request", "message_count", len(req.Messages), "tool_count", len(req.Tools))
+
+	start := time.Now()
+	c.metrics.recordRequest(ctx, genAIAttrs)
+
+	// Errors after this point are recorded here, errors while streaming in the response iterator
+	defer func() {
+		if err != nil {
+			log.Info("Chat completion request failed", "error", err)
+			c.metrics.recordEnd(ctx, genAIAttrs, start, nil, err)
+			sa.genAI(attribute.String("error.type", errorType(err)))
+			span.End()
+		}
+	}()
+
 	var config genai.GenerateContentConfig
 	if req.Temperature != nil {
 		config.Temperature = gai.Ptr(float32(*req.Temperature))
-		span.SetAttributes(attribute.Float64("ai.temperature", float64(*req.Temperature)))
+		sa.legacy(attribute.Float64("ai.temperature", float64(*req.Temperature)))
+		sa.genAI(attribute.Float64("gen_ai.request.temperature", float64(*req.Temperature)))
 	}
 	if req.System != nil {
 		config.SystemInstruction = genai.NewContentFromText(*req.System, genai.RoleUser)
-		span.SetAttributes(attribute.Bool("ai.has_system_prompt", true))
-		span.SetAttributes(attribute.String("ai.system_prompt", *req.System))
+		sa.legacy(attribute.Bool("ai.has_system_prompt", true))
+		if content, ok := c.content.system(*req.System); ok {
+			sa.legacy(attribute.String("ai.system_prompt", content))
+			sa.genAI(attribute.String("gen_ai.system_instructions", content))
+		}
 	}
 	if req.MaxCompletionTokens != nil {
 		config.MaxOutputTokens = int32(*req.MaxCompletionTokens)
-		span.SetAttributes(attribute.Int("ai.max_completion_tokens", *req.MaxCompletionTokens))
+		sa.legacy(attribute.Int("ai.max_completion_tokens", *req.MaxCompletionTokens))
+		sa.genAI(attribute.Int("gen_ai.request.max_tokens", *req.MaxCompletionTokens))
+	}
+
+	if len(c.responseModalities) > 0 {
+		var modalities []string
+		for _, m := range c.responseModalities {
+			modalities = append(modalities, string(m))
+		}
+		config.ResponseModalities = modalities
+		sa.legacy(attribute.StringSlice("ai.response_modalities", modalities))
+	}
+
+	if c.modelCatalog != nil {
+		err = c.modelCatalog.validateChatComplete(ctx, c.model, req, c.responseModalities, c.codeExecution)
+	} else if spec, ok := LookupModel(c.model); ok {
+		err = validateChatComplete(c.model, spec.Capabilities, spec.OutputTokenLimit, req, c.responseModalities, c.codeExecution)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "model validation failed")
+		return ChatCompleteResponse{}, fmt.Errorf("invalid request for model: %w", err)
 	}
 
 	if len(req.Tools) > 0 {
@@ -85,7 +282,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "tool conversion failed")
-			return gai.ChatCompleteResponse{}, fmt.Errorf("error converting tools: %w", err)
+			return ChatCompleteResponse{}, fmt.Errorf("error converting tools: %w", err)
 		}
 		config.Tools = tools
 
@@ -95,22 +292,68 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 			toolNames = append(toolNames, tool.Name)
 		}
 		sort.Strings(toolNames)
-		span.SetAttributes(
+		sa.legacy(
 			attribute.Int("ai.tool_count", len(req.Tools)),
 			attribute.StringSlice("ai.tools", toolNames),
 		)
 	}
 
+	if c.codeExecution {
+		config.Tools = append(config.Tools, &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}})
+		sa.legacy(attribute.Bool("ai.code_execution", true))
+	}
+
+	if c.urlContext {
+		config.Tools = append(config.Tools, &genai.Tool{URLContext: &genai.URLContext{}})
+		sa.legacy(attribute.Bool("ai.url_context", true))
+	}
+
+	if req.FunctionCalling != nil {
+		if err := validateFunctionCalling(*req.FunctionCalling, req.Tools); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "function calling validation failed")
+			return ChatCompleteResponse{}, fmt.Errorf("invalid function calling config: %w", err)
+		}
+		config.ToolConfig = &genai.ToolConfig{
+			FunctionCallingConfig: &genai.FunctionCallingConfig{
+				Mode:                 req.FunctionCalling.Mode,
+				AllowedFunctionNames: req.FunctionCalling.AllowedFunctionNames,
+			},
+		}
+		sa.legacy(attribute.String("ai.function_calling_mode", string(req.FunctionCalling.Mode)))
+		if len(req.FunctionCalling.AllowedFunctionNames) > 0 {
+			sa.legacy(attribute.StringSlice("ai.allowed_function_names", req.FunctionCalling.AllowedFunctionNames))
+		}
+	}
+
+	if c.streamToolCallArgs && len(req.Tools) > 0 {
+		if config.ToolConfig == nil {
+			config.ToolConfig = &genai.ToolConfig{}
+		}
+		if config.ToolConfig.FunctionCallingConfig == nil {
+			config.ToolConfig.FunctionCallingConfig = &genai.FunctionCallingConfig{}
+		}
+		config.ToolConfig.FunctionCallingConfig.StreamFunctionCallArguments = gai.Ptr(true)
+		sa.legacy(attribute.Bool("ai.stream_tool_call_args", true))
+	}
+
 	if req.ResponseSchema != nil {
 		responseSchema, err := schema.ConvertResponseSchema(*req.ResponseSchema)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "response schema conversion failed")
-			return gai.ChatCompleteResponse{}, fmt.Errorf("error converting response schema: %w", err)
+			return ChatCompleteResponse{}, fmt.Errorf("error converting response schema: %w", err)
 		}
 		config.ResponseMIMEType = "application/json"
 		config.ResponseSchema = responseSchema
-		span.SetAttributes(attribute.Bool("ai.has_response_schema", true))
+		sa.legacy(attribute.Bool("ai.has_response_schema", true))
+		sa.genAI(attribute.String("gen_ai.output.type", "json"))
+	}
+
+	if err := validateToolResults(req.Messages); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "tool result validation failed")
+		return ChatCompleteResponse{}, fmt.Errorf("invalid tool results: %w", err)
 	}
 
 	var history []*genai.Content
@@ -137,7 +380,7 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 				if err := json.Unmarshal(toolCall.Args, &args); err != nil {
 					span.RecordError(err)
 					span.SetStatus(codes.Error, "request tool call args unmarshal failed")
-					return gai.ChatCompleteResponse{}, fmt.Errorf("error unmarshaling request tool call args: %w", err)
+					return ChatCompleteResponse{}, fmt.Errorf("error unmarshaling request tool call args: %w", err)
 				}
 				part := genai.NewPartFromFunctionCall(toolCall.Name, args)
 				part.FunctionCall.ID = toolCall.ID
@@ -145,9 +388,11 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 
 			case gai.MessagePartTypeToolResult:
 				toolResult := part.ToolResult()
-				res := map[string]any{"output": toolResult.Content}
-				if toolResult.Err != nil {
-					res = map[string]any{"error": toolResult.Err.Error()}
+				res, err := c.toolResultEncoder(toolResult)
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, "tool result encoding failed")
+					return ChatCompleteResponse{}, fmt.Errorf("error encoding tool result: %w", err)
 				}
 				part := genai.NewPartFromFunctionResponse(toolResult.Name, res)
 				part.FunctionResponse.ID = toolResult.ID
@@ -158,7 +403,21 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 				if err != nil {
 					span.RecordError(err)
 					span.SetStatus(codes.Error, "data read failed")
-					return gai.ChatCompleteResponse{}, fmt.Errorf("error reading request data: %w", err)
+					return ChatCompleteResponse{}, fmt.Errorf("error reading request data: %w", err)
+				}
+				// Rewind, so the same messages can be sent again, for example by [ToolRunner]
+				if seeker, ok := part.Data.(io.Seeker); ok {
+					if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+						span.RecordError(err)
+						span.SetStatus(codes.Error, "data rewind failed")
+						return ChatCompleteResponse{}, fmt.Errorf("error rewinding request data: %w", err)
+					}
+				}
+
+				if c.multimodalToolResults && len(content.Parts) > 0 && content.Parts[len(content.Parts)-1].FunctionResponse != nil {
+					res := content.Parts[len(content.Parts)-1].FunctionResponse
+					res.Parts = append(res.Parts, genai.NewFunctionResponsePartFromBytes(data, part.MIMEType))
+					continue
 				}
 
 				part := &genai.Part{
@@ -169,11 +428,38 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 				}
 				content.Parts = append(content.Parts, part)
 
+			case MessagePartTypeExecutableCode:
+				code, err := ExecutableCodeFromPart(part)
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, "executable code read failed")
+					return ChatCompleteResponse{}, fmt.Errorf("error reading request executable code: %w", err)
+				}
+				content.Parts = append(content.Parts, genai.NewPartFromExecutableCode(code.Code, code.Language))
+
+			case MessagePartTypeCodeExecutionResult:
+				result, err := CodeExecutionResultFromPart(part)
+				if err != nil {
+					span.RecordError(err)
+					span.SetStatus(codes.Error, "code execution result read failed")
+					return ChatCompleteResponse{}, fmt.Errorf("error reading request code execution result: %w", err)
+				}
+				content.Parts = append(content.Parts, genai.NewPartFromCodeExecutionResult(result.Outcome, result.Output))
+
+			case MessagePartTypeToolCallDelta:
+				// The complete tool call part follows the deltas, so they're not needed
+				continue
+
 			default:
 				panic("unknown part type " + part.Type)
 			}
 		}
 
+		// Gemini expects function responses in the same order as the function calls in the previous turn
+		if content.Role == genai.RoleUser && len(history) > 0 {
+			sortFunctionResponses(&content, history[len(history)-1])
+		}
+
 		history = append(history, &content)
 	}
 
@@ -181,26 +467,135 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 	lastContent := history[len(history)-1]
 	history = history[:len(history)-1]
 
-	chat, err := c.Client.Chats.Create(ctx, string(c.model), &config, history)
+	if c.messageEvents {
+		addMessageEvents(span, genAISystem(c.Client), c.content, req)
+	}
+
+	log.Debug("Sending chat completion request", "history_length", len(history), "part_count", len(lastContent.Parts))
+
+	chat, err := c.Client.Chats.Create(ctx, modelName(c.Client, c.model), &config, history)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "chat session creation failed")
-		return gai.ChatCompleteResponse{}, err
+		return ChatCompleteResponse{}, err
 	}
 
 	meta := &gai.ChatCompleteResponseMetadata{}
+	googleMeta := &ChatCompleteResponseMetadata{}
 
 	res := gai.NewChatCompleteResponse(func(yield func(gai.MessagePart, error) bool) {
 		defer span.End()
 
-		for chunk, err := range chat.SendStream(ctx, lastContent.Parts...) {
+		// event on the span, with the time elapsed since the request started
+		event := func(name string, attrs ...attribute.KeyValue) {
+			attrs = append(attrs, attribute.Float64("ai.elapsed_ms", milliseconds(time.Since(start))))
+			span.AddEvent(name, trace.WithAttributes(attrs...))
+		}
+
+		// firstToken records the time to the first content part of any type, if not already recorded
+		firstToken := func() {
+			if googleMeta.TimeToFirstToken > 0 {
+				return
+			}
+			googleMeta.TimeToFirstToken = time.Since(start)
+			c.metrics.recordFirstToken(ctx, genAIAttrs, start)
+			sa.legacy(attribute.Float64("ai.time_to_first_token_ms", milliseconds(googleMeta.TimeToFirstToken)))
+		}
+
+		var stats streamStats
+		var firstText, firstToolCall bool
+
+		var streamErr error
+		var finishReason genai.FinishReason
+		var choiceText strings.Builder
+		var choiceToolCalls []gai.ToolCall
+		defer func() {
+			event("stream_end", stats.attributes()...)
+			sa.legacy(stats.attributes()...)
+
+			c.metrics.recordEnd(ctx, genAIAttrs, start, &googleMeta.Usage, streamErr)
+
+			if streamErr != nil {
+				log.Info("Chat completion stream failed", "error", streamErr, "duration", time.Since(start))
+				sa.genAI(attribute.String("error.type", errorType(streamErr)))
+				return
+			}
+			if finishReason != "" {
+				sa.genAI(attribute.StringSlice("gen_ai.response.finish_reasons", []string{genAIFinishReason(finishReason)}))
+			}
+			log.Debug("Chat completion stream ended",
+				"duration", time.Since(start),
+				"finish_reason", finishReason,
+				"prompt_tokens", googleMeta.Usage.PromptTokens.Total,
+				"completion_tokens", googleMeta.Usage.CompletionTokens.Total,
+				"tool_call_count", len(googleMeta.ToolCallIDs),
+				"chunk_count", stats.chunks,
+				"time_to_first_token", googleMeta.TimeToFirstToken,
+			)
+			if c.messageEvents {
+				addChoiceEvent(span, genAISystem(c.Client), c.content, finishReason, choiceText.String(), choiceToolCalls)
+			}
+		}()
+
+		// Tool call IDs must be unique within the response, so results can be matched to calls
+		ids := toolCallIDs{}
+		newToolCallID := func(id string) (string, error) {
+			id, err := ids.unique(id, c.idGenerator)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "response tool call ID generation failed")
+				streamErr = err
+				return "", fmt.Errorf("error generating response tool call ID: %w", err)
+			}
+			googleMeta.ToolCallIDs = append(googleMeta.ToolCallIDs, id)
+			sa.legacy(attribute.Int("ai.tool_call_count", len(googleMeta.ToolCallIDs)))
+			return id, nil
+		}
+
+		// yieldToolCall part with the complete args, returning false if the iteration should stop
+		yieldToolCall := func(id string, call *genai.FunctionCall) bool {
+			args, err := json.Marshal(call.Args)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "response tool call args marshal failed")
+				streamErr = err
+				yield(gai.MessagePart{}, fmt.Errorf("error marshaling response tool call args: %w", err))
+				return false
+			}
+			c.metrics.recordToolCall(ctx, genAIAttrs, call.Name)
+			toolLog := log.With("tool_name", call.Name, "tool_call_id", id)
+			if content, ok := c.content.full(string(args)); ok {
+				toolLog = toolLog.With("tool_args", content)
+			}
+			toolLog.Debug("Received tool call")
+			if c.messageEvents {
+				choiceToolCalls = append(choiceToolCalls, gai.ToolCall{ID: id, Name: call.Name, Args: args})
+			}
+			return yield(gai.ToolCallPart(id, call.Name, args), nil)
+		}
+
+		// partialCall is the tool call currently streaming its arguments, if any
+		var partialCall *partialToolCall
+
+		event("stream_open")
+		for chunk, err := range chat.SendStream(trackRetries(ctx), lastContent.Parts...) {
 			if err != nil {
 				span.RecordError(err)
 				span.SetStatus(codes.Error, "chat stream send failed")
+				streamErr = err
 				yield(gai.MessagePart{}, err)
 				return
 			}
 
+			if stats.chunk(time.Now()) {
+				event("first_chunk")
+				log.Debug("Chat completion stream started", "time_to_first_chunk", time.Since(start))
+				sa.genAI(
+					attribute.String("gen_ai.response.id", chunk.ResponseID),
+					attribute.String("gen_ai.response.model", chunk.ModelVersion),
+				)
+			}
+
 			// Extract token usage from the response
 			// Google GenAI sends usage metadata with every chunk during streaming:
 			// - Early chunks show prompt tokens only (with minor variations between chunks)
@@ -212,11 +607,42 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 					ThoughtsTokens:   int(chunk.UsageMetadata.ThoughtsTokenCount),
 					CompletionTokens: int(chunk.UsageMetadata.CandidatesTokenCount),
 				}
-				span.SetAttributes(
+				sa.legacy(
 					attribute.Int("ai.prompt_tokens", int(chunk.UsageMetadata.PromptTokenCount)),
 					attribute.Int("ai.thoughts_tokens", int(chunk.UsageMetadata.ThoughtsTokenCount)),
 					attribute.Int("ai.completion_tokens", int(chunk.UsageMetadata.CandidatesTokenCount)),
 				)
+
+				googleMeta.Usage = convertUsage(chunk.UsageMetadata)
+				sa.legacy(usageAttributes(googleMeta.Usage)...)
+				sa.genAI(
+					attribute.Int("gen_ai.usage.input_tokens", googleMeta.Usage.PromptTokens.Total+googleMeta.Usage.ToolUsePromptTokens.Total),
+					attribute.Int("gen_ai.usage.output_tokens", googleMeta.Usage.CompletionTokens.Total+googleMeta.Usage.ThoughtsTokens),
+				)
+
+				if c.pricing != nil {
+					cost := estimateCost(*c.pricing, googleMeta.Usage)
+					googleMeta.Cost = &cost
+					sa.legacy(attribute.Float64("ai.estimated_cost_usd", cost.Total))
+				}
+			}
+
+			if len(chunk.Candidates) > 0 && chunk.Candidates[0].URLContextMetadata != nil {
+				// The metadata may be repeated across chunks, so only add URLs not seen before
+				for _, m := range chunk.Candidates[0].URLContextMetadata.URLMetadata {
+					if slices.ContainsFunc(googleMeta.URLContext, func(u URLMetadata) bool { return u.URL == m.RetrievedURL }) {
+						continue
+					}
+					googleMeta.URLContext = append(googleMeta.URLContext, URLMetadata{
+						URL:    m.RetrievedURL,
+						Status: m.URLRetrievalStatus,
+					})
+				}
+				sa.legacy(attribute.Int("ai.url_context_count", len(googleMeta.URLContext)))
+			}
+
+			if len(chunk.Candidates) > 0 && chunk.Candidates[0].FinishReason != "" {
+				finishReason = chunk.Candidates[0].FinishReason
 			}
 
 			if len(chunk.Candidates) == 0 || chunk.Candidates[0].Content == nil {
@@ -225,38 +651,231 @@ func (c *ChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRe
 
 			for _, part := range chunk.Candidates[0].Content.Parts {
 				if part.Text != "" {
+					if c.messageEvents {
+						choiceText.WriteString(part.Text)
+					}
+					firstToken()
+					if !firstText {
+						event("first_text")
+						firstText = true
+					}
 					if !yield(gai.TextMessagePart(part.Text), nil) {
 						return
 					}
 				}
 
 				if part.FunctionCall != nil {
-					args, err := json.Marshal(part.FunctionCall.Args)
+					call := part.FunctionCall
+
+					firstToken()
+					if !firstToolCall {
+						event("first_tool_call", attribute.String("ai.tool_name", call.Name))
+						firstToolCall = true
+					}
+
+					if c.streamToolCallArgs && (partialCall != nil || isPartialFunctionCall(call)) {
+						// A call with another ID or name before the partial call is done cuts it off with incomplete args,
+						// so it can't be executed
+						if partialCall != nil && partialCall.isOther(call) {
+							err := fmt.Errorf("response tool call %v was cut off by tool call %v with incomplete args", partialCall.Name, call.Name)
+							span.RecordError(err)
+							span.SetStatus(codes.Error, "response tool call partial args failed")
+							streamErr = err
+							yield(gai.MessagePart{}, err)
+							return
+						}
+
+						if partialCall == nil {
+							id, err := newToolCallID(call.ID)
+							if err != nil {
+								yield(gai.MessagePart{}, err)
+								return
+							}
+							partialCall = newPartialToolCall(id, call.ID, call.Name)
+						}
+
+						deltas, err := partialCall.add(call)
+						if err != nil {
+							span.RecordError(err)
+							span.SetStatus(codes.Error, "response tool call partial args failed")
+							streamErr = err
+							yield(gai.MessagePart{}, fmt.Errorf("error assembling response tool call args: %w", err))
+							return
+						}
+						for _, delta := range deltas {
+							if !yield(ToolCallDeltaPart(delta), nil) {
+								return
+							}
+						}
+
+						if !partialCall.done {
+							continue
+						}
+
+						done := partialCall
+						partialCall = nil
+						if !yieldToolCall(done.ID, done.functionCall()) {
+							return
+						}
+						continue
+					}
+
+					id, err := newToolCallID(call.ID)
 					if err != nil {
-						span.RecordError(err)
-						span.SetStatus(codes.Error, "response tool call args marshal failed")
-						yield(gai.MessagePart{}, fmt.Errorf("error marshaling response tool call args: %w", err))
+						yield(gai.MessagePart{}, err)
+						return
+					}
+					if !yieldToolCall(id, call) {
+						return
+					}
+				}
+
+				if part.InlineData != nil {
+					firstToken()
+					if !yield(gai.DataMessagePart(part.InlineData.MIMEType, bytes.NewReader(part.InlineData.Data)), nil) {
+						return
+					}
+				}
+
+				if part.ExecutableCode != nil {
+					code := ExecutableCode{
+						Language: part.ExecutableCode.Language,
+						Code:     part.ExecutableCode.Code,
+					}
+					firstToken()
+					if !yield(ExecutableCodePart(code), nil) {
 						return
 					}
-					id := part.FunctionCall.ID
-					if id == "" {
-						id = createRandomID()
+				}
+
+				if part.CodeExecutionResult != nil {
+					result := CodeExecutionResult{
+						Outcome: part.CodeExecutionResult.Outcome,
+						Output:  part.CodeExecutionResult.Output,
 					}
-					if !yield(gai.ToolCallPart(id, part.FunctionCall.Name, args), nil) {
+					firstToken()
+					if !yield(CodeExecutionResultPart(result), nil) {
 						return
 					}
 				}
 			}
 		}
+
+		// A partial tool call still open when the stream ends has incomplete args, so it can't be executed
+		if partialCall != nil {
+			err := fmt.Errorf("response tool call %v ended with incomplete args", partialCall.Name)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "response tool call partial args failed")
+			streamErr = err
+			yield(gai.MessagePart{}, err)
+		}
 	})
 
 	res.Meta = meta
 
-	return res, nil
+	return ChatCompleteResponse{
+		ChatCompleteResponse: res,
+		GoogleMeta:           googleMeta,
+	}, nil
 }
 
 var _ gai.ChatCompleter = (*ChatCompleter)(nil)
 
-func createRandomID() string {
-	return fmt.Sprintf("%x", sha256.Sum256([]byte(time.Now().Format(time.RFC3339Nano))))
+// validateToolResults checks that each tool result in the messages matches a previous tool call,
+// by ID if the result has one, and by name.
+func validateToolResults(messages []gai.Message) error {
+	var toolCalls []gai.ToolCall
+	for i, m := range messages {
+		for _, part := range m.Parts {
+			switch part.Type {
+			case gai.MessagePartTypeToolCall:
+				toolCalls = append(toolCalls, part.ToolCall())
+
+			case gai.MessagePartTypeToolResult:
+				toolResult := part.ToolResult()
+
+				if toolResult.ID == "" {
+					if !slices.ContainsFunc(toolCalls, func(toolCall gai.ToolCall) bool { return toolCall.Name == toolResult.Name }) {
+						return fmt.Errorf("tool result for %v in message %v has no previous tool call with that name", toolResult.Name, i)
+					}
+					continue
+				}
+
+				j := slices.IndexFunc(toolCalls, func(toolCall gai.ToolCall) bool { return toolCall.ID == toolResult.ID })
+				if j < 0 {
+					return fmt.Errorf("tool result with ID %v in message %v has no previous tool call with that ID", toolResult.ID, i)
+				}
+				if toolCalls[j].Name != toolResult.Name {
+					return fmt.Errorf("tool result with ID %v in message %v has name %v, but the tool call has name %v",
+						toolResult.ID, i, toolResult.Name, toolCalls[j].Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sortFunctionResponses in content in the order of the function calls in the previous content.
+// Other parts keep their positions.
+func sortFunctionResponses(content, previous *genai.Content) {
+	var calls []*genai.FunctionCall
+	for _, part := range previous.Parts {
+		if part.FunctionCall != nil {
+			calls = append(calls, part.FunctionCall)
+		}
+	}
+	if len(calls) < 2 {
+		return
+	}
+
+	var indexes []int
+	var responses []*genai.Part
+	for i, part := range content.Parts {
+		if part.FunctionResponse != nil {
+			indexes = append(indexes, i)
+			responses = append(responses, part)
+		}
+	}
+
+	callIndex := func(res *genai.FunctionResponse) int {
+		return slices.IndexFunc(calls, func(call *genai.FunctionCall) bool {
+			if res.ID != "" {
+				return call.ID == res.ID
+			}
+			return call.Name == res.Name
+		})
+	}
+	slices.SortStableFunc(responses, func(a, b *genai.Part) int {
+		return callIndex(a.FunctionResponse) - callIndex(b.FunctionResponse)
+	})
+
+	for i, j := range indexes {
+		content.Parts[j] = responses[i]
+	}
+}
+
+// validateFunctionCalling config against the tools in the request.
+func validateFunctionCalling(config genai.FunctionCallingConfig, tools []gai.Tool) error {
+	switch config.Mode {
+	case genai.FunctionCallingConfigModeAuto, genai.FunctionCallingConfigModeNone:
+		if len(config.AllowedFunctionNames) > 0 {
+			return fmt.Errorf("allowed function names cannot be set with mode %v", config.Mode)
+		}
+
+	case genai.FunctionCallingConfigModeAny, genai.FunctionCallingConfigModeValidated:
+		if len(tools) == 0 {
+			return fmt.Errorf("mode %v requires tools in the request", config.Mode)
+		}
+
+	default:
+		return fmt.Errorf("unknown mode %q", config.Mode)
+	}
+
+	for _, name := range config.AllowedFunctionNames {
+		if !slices.ContainsFunc(tools, func(tool gai.Tool) bool { return tool.Name == name }) {
+			return fmt.Errorf("allowed function name %v is not a tool in the request", name)
+		}
+	}
+
+	return nil
 }
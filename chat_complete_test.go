@@ -4,10 +4,16 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"google.golang.org/genai"
 	"maragu.dev/gai"
 	"maragu.dev/gai/tools"
 	"maragu.dev/is"
@@ -219,6 +225,107 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.NotError(t, result.Err)
 	})
 
+	t.Run("attaches data parts to the preceding tool result with multimodal tool results", func(t *testing.T) {
+		baseURL, requests := newRecordingChatServer(t, http.StatusOK, toolCallStream)
+		c := google.NewClient(google.NewClientOptions{BaseURL: baseURL, Key: "test"})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:                 google.ChatCompleteModelGemini2_5Flash,
+			MultimodalToolResults: true,
+		})
+
+		resultMessage := gai.NewUserToolResultMessage(gai.ToolResult{ID: "1", Name: "read_file", Content: `{"path":"logo.jpg"}`})
+		resultMessage.Parts = append(resultMessage.Parts, gai.DataMessagePart("image/jpeg", bytes.NewReader(image)))
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What's in logo.jpg?"),
+				{Role: gai.MessageRoleModel, Parts: []gai.MessagePart{gai.ToolCallPart("1", "read_file", json.RawMessage(`{"path":"logo.jpg"}`))}},
+				resultMessage,
+			},
+		})
+
+		var req struct {
+			Contents []*genai.Content
+		}
+		is.NotError(t, json.Unmarshal(requests()[0], &req))
+		is.Equal(t, 3, len(req.Contents))
+
+		parts := req.Contents[2].Parts
+		is.Equal(t, 1, len(parts))
+		res := parts[0].FunctionResponse
+		is.NotNil(t, res)
+		is.Equal(t, "read_file", res.Name)
+		is.Equal[any](t, "logo.jpg", res.Response["output"].(map[string]any)["path"])
+		is.Equal(t, 1, len(res.Parts))
+		is.Equal(t, "image/jpeg", res.Parts[0].InlineData.MIMEType)
+		is.True(t, bytes.Equal(image, res.Parts[0].InlineData.Data), "should have image data")
+	})
+
+	t.Run("uses the ID generator for unique tool call IDs", func(t *testing.T) {
+		c := newClient(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			IDGenerator: google.NewCounterIDGenerator("call_"),
+			Model:       google.ChatCompleteModelGemini2_5Flash,
+		})
+
+		root, err := os.OpenRoot("testdata")
+		is.NotError(t, err)
+
+		req := gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What is in the readme.txt and logo.jpg files? Read them both at the same time."),
+			},
+			Temperature: gai.Ptr(gai.Temperature(0)),
+			Tools: []gai.Tool{
+				tools.NewReadFile(root),
+			},
+		}
+
+		res, err := cc.ChatComplete(t.Context(), req)
+		is.NotError(t, err)
+
+		var ids []string
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			if part.Type == gai.MessagePartTypeToolCall {
+				ids = append(ids, part.ToolCall().ID)
+			}
+		}
+
+		is.Equal(t, 2, len(ids))
+		is.Equal(t, "call_1", ids[0])
+		is.Equal(t, "call_2", ids[1])
+	})
+
+	t.Run("errors if the ID generator can't generate unique tool call IDs", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL: newChatServer(t, http.StatusOK, `data: {"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"echo","args":{}}},{"functionCall":{"name":"echo","args":{}}}]},"finishReason":"STOP"}]}
+
+`),
+			Key: "test",
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			IDGenerator: func() string { return "same" },
+			Model:       google.ChatCompleteModelGemini2_5Flash,
+		})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Echo twice.")},
+		})
+		is.NotError(t, err)
+
+		var ids []string
+		for part, err := range res.Parts() {
+			if err != nil {
+				is.True(t, strings.Contains(err.Error(), "no unique tool call ID"), err.Error())
+				break
+			}
+			ids = append(ids, part.ToolCall().ID)
+		}
+		is.EqualSlice(t, []string{"same"}, ids)
+	})
+
 	t.Run("can use a system prompt", func(t *testing.T) {
 		cc := newChatCompleter(t)
 
@@ -464,6 +571,530 @@ func TestChatCompleter_ChatComplete(t *testing.T) {
 		is.True(t, res.Meta.Usage.CompletionTokens > maxCompletionTokens, "should exceed limit when not constrained")
 		is.True(t, len(fullOutput) > len(limitedOutput), "should produce more output without limit")
 	})
+
+	t.Run("can execute code", func(t *testing.T) {
+		c := newClient(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			CodeExecution: true,
+			Model:         google.ChatCompleteModelGemini2_5Flash,
+		})
+
+		req := gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What is the sum of the first 50 prime numbers? Generate and run code for the calculation."),
+			},
+			Temperature: gai.Ptr(gai.Temperature(0)),
+		}
+
+		res, err := cc.ChatComplete(t.Context(), req)
+		is.NotError(t, err)
+
+		var output string
+		var code google.ExecutableCode
+		var result google.CodeExecutionResult
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			switch part.Type {
+			case gai.MessagePartTypeText:
+				output += part.Text()
+
+			case google.MessagePartTypeExecutableCode:
+				code, err = google.ExecutableCodeFromPart(part)
+				is.NotError(t, err)
+
+			case google.MessagePartTypeCodeExecutionResult:
+				result, err = google.CodeExecutionResultFromPart(part)
+				is.NotError(t, err)
+
+			default:
+				t.Fatal("unexpected message parts")
+			}
+		}
+
+		t.Log(code.Code)
+		is.Equal(t, genai.LanguagePython, code.Language)
+		is.True(t, len(code.Code) > 0, "should have code")
+		is.Equal(t, genai.OutcomeOK, result.Outcome)
+		is.True(t, strings.Contains(result.Output, "5117"), result.Output)
+		is.True(t, strings.Contains(output, "5117"), output)
+	})
+
+	t.Run("can read URLs with the URL context tool", func(t *testing.T) {
+		c := newClient(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:      google.ChatCompleteModelGemini2_5Flash,
+			URLContext: true,
+		})
+
+		req := google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{
+					gai.NewUserTextMessage("What is the title of the page at https://www.maragu.dev/? Only answer with the title."),
+				},
+				Temperature: gai.Ptr(gai.Temperature(0)),
+			},
+		}
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), req)
+		is.NotError(t, err)
+
+		var output string
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			switch part.Type {
+			case gai.MessagePartTypeText:
+				output += part.Text()
+
+			default:
+				t.Fatal("unexpected message parts")
+			}
+		}
+
+		t.Log(output)
+		is.NotNil(t, res.GoogleMeta)
+		is.Equal(t, 1, len(res.GoogleMeta.URLContext))
+		is.Equal(t, "https://www.maragu.dev/", res.GoogleMeta.URLContext[0].URL)
+		is.Equal(t, genai.URLRetrievalStatusSuccess, res.GoogleMeta.URLContext[0].Status)
+	})
+
+	t.Run("can force a tool call", func(t *testing.T) {
+		cc := newChatCompleter(t)
+
+		root, err := os.OpenRoot("testdata")
+		is.NotError(t, err)
+
+		req := google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{
+					gai.NewUserTextMessage("Hi!"),
+				},
+				Temperature: gai.Ptr(gai.Temperature(0)),
+				Tools: []gai.Tool{
+					tools.NewListDir(root),
+					tools.NewReadFile(root),
+				},
+			},
+			FunctionCalling: &genai.FunctionCallingConfig{
+				Mode:                 genai.FunctionCallingConfigModeAny,
+				AllowedFunctionNames: []string{"list_dir"},
+			},
+		}
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), req)
+		is.NotError(t, err)
+
+		var toolCalls []gai.ToolCall
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			if part.Type == gai.MessagePartTypeToolCall {
+				toolCalls = append(toolCalls, part.ToolCall())
+			}
+		}
+
+		is.True(t, len(toolCalls) > 0, "should have tool calls")
+		for _, toolCall := range toolCalls {
+			is.Equal(t, "list_dir", toolCall.Name)
+		}
+	})
+
+	t.Run("can forbid tool calls", func(t *testing.T) {
+		cc := newChatCompleter(t)
+
+		root, err := os.OpenRoot("testdata")
+		is.NotError(t, err)
+
+		req := google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{
+					gai.NewUserTextMessage("What is in the readme.txt file?"),
+				},
+				Temperature: gai.Ptr(gai.Temperature(0)),
+				Tools: []gai.Tool{
+					tools.NewReadFile(root),
+				},
+			},
+			FunctionCalling: &genai.FunctionCallingConfig{
+				Mode: genai.FunctionCallingConfigModeNone,
+			},
+		}
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), req)
+		is.NotError(t, err)
+
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			is.Equal(t, gai.MessagePartTypeText, part.Type)
+		}
+	})
+
+	t.Run("errors on invalid function calling config", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		root, err := os.OpenRoot("testdata")
+		is.NotError(t, err)
+
+		tests := []struct {
+			name   string
+			config genai.FunctionCallingConfig
+			tools  []gai.Tool
+		}{
+			{"unknown mode", genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeUnspecified}, nil},
+			{"any mode without tools", genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAny}, nil},
+			{"allowed names with auto mode", genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAuto, AllowedFunctionNames: []string{"read_file"}}, []gai.Tool{tools.NewReadFile(root)}},
+			{"allowed name not in tools", genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAny, AllowedFunctionNames: []string{"list_dir"}}, []gai.Tool{tools.NewReadFile(root)}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				req := google.ChatCompleteRequest{
+					ChatCompleteRequest: gai.ChatCompleteRequest{
+						Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+						Tools:    test.tools,
+					},
+					FunctionCalling: &test.config,
+				}
+
+				_, err := cc.ChatCompleteGoogle(t.Context(), req)
+				is.True(t, err != nil, "should error")
+				t.Log(err)
+			})
+		}
+	})
+
+	t.Run("can send results for parallel tool calls in any order", func(t *testing.T) {
+		cc := newChatCompleter(t)
+
+		root, err := os.OpenRoot("testdata")
+		is.NotError(t, err)
+
+		req := google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{
+					gai.NewUserTextMessage("What is in the current directory, and what is in the readme.txt file? Call both tools at the same time."),
+				},
+				Temperature: gai.Ptr(gai.Temperature(0)),
+				Tools: []gai.Tool{
+					tools.NewListDir(root),
+					tools.NewReadFile(root),
+				},
+			},
+		}
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), req)
+		is.NotError(t, err)
+
+		var parts []gai.MessagePart
+		var results []gai.ToolResult
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			parts = append(parts, part)
+
+			if part.Type == gai.MessagePartTypeToolCall {
+				toolCall := part.ToolCall()
+				for _, tool := range req.Tools {
+					if tool.Name == toolCall.Name {
+						content, err := tool.Execute(t.Context(), toolCall.Args)
+						results = append(results, gai.ToolResult{ID: toolCall.ID, Name: toolCall.Name, Content: content, Err: err})
+					}
+				}
+			}
+		}
+
+		is.Equal(t, 2, len(res.GoogleMeta.ToolCallIDs))
+		is.Equal(t, 2, len(results))
+		is.Equal(t, results[0].ID, res.GoogleMeta.ToolCallIDs[0])
+		is.Equal(t, results[1].ID, res.GoogleMeta.ToolCallIDs[1])
+
+		resultMessage := gai.NewUserToolResultMessage(results[1])
+		resultMessage.Parts = append(resultMessage.Parts, gai.NewUserToolResultMessage(results[0]).Parts...)
+
+		req.Messages = append(req.Messages, gai.Message{Role: gai.MessageRoleModel, Parts: parts}, resultMessage)
+
+		res, err = cc.ChatCompleteGoogle(t.Context(), req)
+		is.NotError(t, err)
+
+		var output string
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			if part.Type == gai.MessagePartTypeText {
+				output += part.Text()
+			}
+		}
+
+		t.Log(output)
+		is.True(t, strings.Contains(output, "Hi"), output)
+	})
+
+	t.Run("orders function responses like the function calls", func(t *testing.T) {
+		toolCallMessage := func(ids ...string) gai.Message {
+			m := gai.Message{Role: gai.MessageRoleModel}
+			for i, id := range ids {
+				m.Parts = append(m.Parts, gai.ToolCallPart(id, []string{"a", "b", "c"}[i], json.RawMessage(`{}`)))
+			}
+			return m
+		}
+		toolResultPart := func(id, name string) gai.MessagePart {
+			return gai.NewUserToolResultMessage(gai.ToolResult{ID: id, Name: name}).Parts[0]
+		}
+
+		tests := []struct {
+			name     string
+			toolCall gai.Message
+			parts    []gai.MessagePart
+			expected []string
+		}{
+			{"by ID", toolCallMessage("1", "2", "3"), []gai.MessagePart{
+				toolResultPart("3", "c"),
+				toolResultPart("1", "a"),
+				toolResultPart("2", "b"),
+			}, []string{"a", "b", "c"}},
+			{"by name without IDs", toolCallMessage("", ""), []gai.MessagePart{
+				toolResultPart("", "b"),
+				toolResultPart("", "a"),
+			}, []string{"a", "b"}},
+			{"keeps other parts in place", toolCallMessage("1", "2"), []gai.MessagePart{
+				toolResultPart("2", "b"),
+				gai.TextMessagePart("Here you go."),
+				toolResultPart("1", "a"),
+			}, []string{"a", "Here you go.", "b"}},
+			{"keeps a single response", toolCallMessage("1"), []gai.MessagePart{
+				gai.TextMessagePart("Here you go."),
+				toolResultPart("1", "a"),
+			}, []string{"Here you go.", "a"}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				baseURL, requests := newRecordingChatServer(t, http.StatusOK, toolCallStream)
+				c := google.NewClient(google.NewClientOptions{BaseURL: baseURL, Key: "test"})
+				cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+				completeAll(t, cc, gai.ChatCompleteRequest{
+					Messages: []gai.Message{
+						gai.NewUserTextMessage("Call the tools."),
+						test.toolCall,
+						{Role: gai.MessageRoleUser, Parts: test.parts},
+					},
+				})
+
+				var req struct {
+					Contents []*genai.Content
+				}
+				is.NotError(t, json.Unmarshal(requests()[0], &req))
+
+				var actual []string
+				for _, part := range req.Contents[2].Parts {
+					if part.FunctionResponse != nil {
+						actual = append(actual, part.FunctionResponse.Name)
+					} else {
+						actual = append(actual, part.Text)
+					}
+				}
+				is.EqualSlice(t, test.expected, actual)
+			})
+		}
+	})
+
+	t.Run("errors on tool results without a matching tool call", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		toolCallMessage := gai.Message{
+			Role:  gai.MessageRoleModel,
+			Parts: []gai.MessagePart{gai.ToolCallPart("1", "read_file", json.RawMessage(`{"path":"readme.txt"}`))},
+		}
+
+		tests := []struct {
+			name     string
+			messages []gai.Message
+		}{
+			{"no tool call", []gai.Message{
+				gai.NewUserTextMessage("Hi!"),
+				gai.NewUserToolResultMessage(gai.ToolResult{ID: "1", Name: "read_file", Content: "Hi!"}),
+			}},
+			{"unknown ID", []gai.Message{
+				gai.NewUserTextMessage("Hi!"),
+				toolCallMessage,
+				gai.NewUserToolResultMessage(gai.ToolResult{ID: "2", Name: "read_file", Content: "Hi!"}),
+			}},
+			{"wrong name", []gai.Message{
+				gai.NewUserTextMessage("Hi!"),
+				toolCallMessage,
+				gai.NewUserToolResultMessage(gai.ToolResult{ID: "1", Name: "list_dir", Content: "Hi!"}),
+			}},
+			{"unknown name without ID", []gai.Message{
+				gai.NewUserTextMessage("Hi!"),
+				toolCallMessage,
+				gai.NewUserToolResultMessage(gai.ToolResult{Name: "list_dir", Content: "Hi!"}),
+			}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{Messages: test.messages})
+				is.True(t, err != nil, "should error")
+				t.Log(err)
+			})
+		}
+	})
+
+	t.Run("can generate and edit an image", func(t *testing.T) {
+		c := newClient(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:              google.ChatCompleteModelGemini2_5FlashImage,
+			ResponseModalities: []genai.Modality{genai.ModalityText, genai.ModalityImage},
+		})
+
+		req := gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("Draw a simple gopher logo on a white background."),
+			},
+		}
+
+		res, err := cc.ChatComplete(t.Context(), req)
+		is.NotError(t, err)
+
+		var parts []gai.MessagePart
+		var images int
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			parts = append(parts, part)
+
+			if part.Type == gai.MessagePartTypeData {
+				images++
+				is.True(t, strings.HasPrefix(part.MIMEType, "image/"), part.MIMEType)
+			}
+		}
+
+		is.Equal(t, 1, images)
+
+		req.Messages = append(req.Messages,
+			gai.Message{Role: gai.MessageRoleModel, Parts: parts},
+			gai.NewUserTextMessage("Now make the gopher wear a party hat."),
+		)
+
+		res, err = cc.ChatComplete(t.Context(), req)
+		is.NotError(t, err)
+
+		images = 0
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+
+			if part.Type == gai.MessagePartTypeData {
+				images++
+				data, err := io.ReadAll(part.Data)
+				is.NotError(t, err)
+				is.True(t, len(data) > 0, "should have image data")
+			}
+		}
+
+		is.Equal(t, 1, images)
+	})
+
+	t.Run("estimates the cost from the usage and the pricing", func(t *testing.T) {
+		c := newClient(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:   google.ChatCompleteModelGemini2_5Flash,
+			Pricing: &google.ModelPricing{Input: 1_000_000, Output: 1_000_000},
+		})
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+			},
+		})
+		is.NotError(t, err)
+
+		for _, err := range res.Parts() {
+			is.NotError(t, err)
+		}
+
+		// With a price of 1 dollar per token, the cost is the token count
+		is.NotNil(t, res.GoogleMeta.Cost)
+		is.Equal(t, float64(res.Meta.Usage.PromptTokens), res.GoogleMeta.Cost.Input)
+		is.Equal(t, float64(res.Meta.Usage.CompletionTokens+res.Meta.Usage.ThoughtsTokens), res.GoogleMeta.Cost.Output)
+		is.Equal(t, res.GoogleMeta.Cost.Input+res.GoogleMeta.Cost.Output, res.GoogleMeta.Cost.Total)
+	})
+
+	t.Run("breaks down usage by modality", func(t *testing.T) {
+		cc := newChatCompleter(t)
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{
+					{
+						Role: gai.MessageRoleUser,
+						Parts: []gai.MessagePart{
+							gai.TextMessagePart("What's in this image? Answer in one word."),
+							gai.DataMessagePart("image/jpeg", bytes.NewReader(image)),
+						},
+					},
+				},
+			},
+		})
+		is.NotError(t, err)
+
+		for _, err := range res.Parts() {
+			is.NotError(t, err)
+		}
+
+		usage := res.GoogleMeta.Usage
+		is.Equal(t, res.Meta.Usage.PromptTokens, usage.PromptTokens.Total)
+		is.True(t, usage.PromptTokens.Text > 0, "should have text prompt tokens")
+		is.True(t, usage.PromptTokens.Image > 0, "should have image prompt tokens")
+		is.Equal(t, res.Meta.Usage.CompletionTokens, usage.CompletionTokens.Total)
+		is.True(t, usage.TotalTokens > 0, "should have total tokens")
+	})
+}
+
+func TestChatCompleter_ChatComplete_StreamTelemetry(t *testing.T) {
+	t.Run("records stream events and the time to first token", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:        newChatServer(t, http.StatusOK, toolCallStream),
+			Key:            "test",
+			TracerProvider: tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		res, err := cc.ChatCompleteGoogle(t.Context(), google.ChatCompleteRequest{
+			ChatCompleteRequest: gai.ChatCompleteRequest{
+				Messages: []gai.Message{gai.NewUserTextMessage("What's the weather in Copenhagen?")},
+			},
+		})
+		is.NotError(t, err)
+		is.Equal(t, time.Duration(0), res.GoogleMeta.TimeToFirstToken)
+
+		for _, err := range res.Parts() {
+			is.NotError(t, err)
+		}
+		is.True(t, res.GoogleMeta.TimeToFirstToken > 0, "should have time to first token")
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+
+		var names []string
+		for _, e := range spans[0].Events() {
+			names = append(names, e.Name)
+		}
+		is.EqualSlice(t, []string{"stream_open", "first_chunk", "first_text", "first_tool_call", "stream_end"}, names)
+
+		end := attributeMap(spans[0].Events()[4].Attributes)
+		is.Equal(t, int64(2), end["ai.chunk_count"].AsInt64())
+		is.True(t, end["ai.chunk_gap_max_ms"].AsFloat64() >= 0, "should have chunk gap")
+
+		attrs := attributeMap(spans[0].Attributes())
+		is.Equal(t, int64(2), attrs["ai.chunk_count"].AsInt64())
+		is.True(t, attrs["ai.time_to_first_token_ms"].AsFloat64() > 0, "should have time to first token")
+	})
 }
 
 func newChatCompleter(t *testing.T) *google.ChatCompleter {
@@ -473,3 +1104,50 @@ func newChatCompleter(t *testing.T) *google.ChatCompleter {
 	})
 	return cc
 }
+
+// newChatServer is a local stand-in for the streaming endpoint, responding with the status and body.
+// It returns the base URL.
+func newChatServer(t *testing.T, status int, body string) string {
+	t.Helper()
+
+	baseURL, _ := newRecordingChatServer(t, status, body)
+	return baseURL
+}
+
+// newRecordingChatServer is like newChatServer, but also returns a function to get the request bodies received so far.
+func newRecordingChatServer(t *testing.T, status int, body string) (string, func() [][]byte) {
+	t.Helper()
+
+	var lock sync.Mutex
+	var requests [][]byte
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ":streamGenerateContent") {
+			http.NotFound(w, r)
+			return
+		}
+		req, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		requests = append(requests, req)
+		lock.Unlock()
+
+		if status == http.StatusOK {
+			w.Header().Set("Content-Type", "text/event-stream")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, func() [][]byte {
+		lock.Lock()
+		defer lock.Unlock()
+		return requests
+	}
+}
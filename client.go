@@ -3,35 +3,131 @@ package google
 import (
 	"context"
 	"log/slog"
+	"strings"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/genai"
 )
 
 type Client struct {
-	Client *genai.Client
-	log    *slog.Logger
+	Client            *genai.Client
+	content           contentPolicy
+	log               *slog.Logger
+	messageEvents     bool
+	metrics           *metrics
+	spanAttributeMode SpanAttributeMode
+	tracer            trace.Tracer
 }
 
 type NewClientOptions struct {
+	// BaseURL overrides the API endpoint, for example to use a proxy or a local stand-in for tests.
+	BaseURL string
+
+	// ContentCapture is the policy for recording prompt and completion content in spans and logs.
+	// Messages and completions are recorded in message events, see [NewClientOptions.MessageEvents].
+	// Defaults to [ContentCaptureNone].
+	ContentCapture ContentCapture
+
+	// ContentRedactors are applied in order to captured content before it's recorded,
+	// for example [RedactPII] followed by [Truncate].
+	ContentRedactors []Redactor
+
 	Key string
 	Log *slog.Logger
+
+	// MessageEvents adds a span event per message in chat completion requests and responses,
+	// like "gen_ai.user.message" and "gen_ai.choice", following the OpenTelemetry semantic conventions
+	// for generative AI. The events only include message content allowed by [NewClientOptions.ContentCapture].
+	MessageEvents bool
+
+	// MeterProvider for metrics like request counts, durations, and token usage.
+	// Defaults to the global meter provider.
+	MeterProvider metric.MeterProvider
+
+	// RetryOptions for retrying failed requests, for example on rate limits or server errors.
+	// Retries of requests made by this package are logged, but not those made with [Client.Client] directly.
+	// Defaults to no retries.
+	RetryOptions *genai.HTTPRetryOptions
+
+	// SpanAttributeMode selects the attribute keys on spans. Defaults to [SpanAttributeModeLegacy].
+	SpanAttributeMode SpanAttributeMode
+
+	// TracerProvider for spans. Defaults to the global tracer provider.
+	TracerProvider trace.TracerProvider
+
+	// Project and Location select the Vertex AI backend instead of the Gemini API,
+	// using application default credentials. Some features are only available on Vertex AI,
+	// for example streaming tool call arguments.
+	Project  string
+	Location string
 }
 
 func NewClient(opts NewClientOptions) *Client {
 	if opts.Log == nil {
 		opts.Log = slog.New(slog.DiscardHandler)
 	}
+	if opts.MeterProvider == nil {
+		opts.MeterProvider = otel.GetMeterProvider()
+	}
+	if opts.ContentCapture == "" {
+		opts.ContentCapture = ContentCaptureNone
+	}
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+	if opts.SpanAttributeMode == "" {
+		opts.SpanAttributeMode = SpanAttributeModeLegacy
+	}
+
+	config := &genai.ClientConfig{
+		APIKey:      opts.Key,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: opts.BaseURL, RetryOptions: opts.RetryOptions},
+	}
+	if opts.Project != "" {
+		config = &genai.ClientConfig{
+			Backend:     genai.BackendVertexAI,
+			HTTPOptions: genai.HTTPOptions{BaseURL: opts.BaseURL, RetryOptions: opts.RetryOptions},
+			Project:     opts.Project,
+			Location:    opts.Location,
+		}
+	}
 
-	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
-		APIKey:  opts.Key,
-		Backend: genai.BackendGeminiAPI,
-	})
+	client, err := genai.NewClient(context.Background(), config)
 	if err != nil {
 		panic(err)
 	}
 
+	// The SDK retries inside its HTTP client, which is shared with the client config, so wrap its transport to log them,
+	// see trackRetries
+	if opts.RetryOptions != nil {
+		httpClient := client.ClientConfig().HTTPClient
+		httpClient.Transport = newRetryLogTransport(httpClient.Transport, opts.Log)
+	}
+
 	return &Client{
-		Client: client,
-		log:    opts.Log,
+		Client:            client,
+		content:           contentPolicy{capture: opts.ContentCapture, redactors: opts.ContentRedactors},
+		log:               opts.Log,
+		messageEvents:     opts.MessageEvents,
+		metrics:           newMetrics(opts.MeterProvider),
+		spanAttributeMode: opts.SpanAttributeMode,
+		tracer:            opts.TracerProvider.Tracer("maragu.dev/gai-google"),
+	}
+}
+
+// isVertexAI returns whether the client uses the Vertex AI backend.
+func isVertexAI(client *genai.Client) bool {
+	return client.ClientConfig().Backend == genai.BackendVertexAI
+}
+
+// modelName for the backend of the client.
+// Model constants in this package use the Gemini API "models/" prefix, which Vertex AI doesn't use for Google models.
+func modelName[T ~string](client *genai.Client, model T) string {
+	if isVertexAI(client) {
+		return strings.TrimPrefix(string(model), "models/")
 	}
+	return string(model)
 }
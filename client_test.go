@@ -1,7 +1,16 @@
 package google_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"maragu.dev/env"
@@ -30,6 +39,60 @@ func newClient(t *testing.T) *google.Client {
 	})
 }
 
+// newVertexClient for features only available on Vertex AI, skipping the test if no project is configured.
+func newVertexClient(t *testing.T) *google.Client {
+	t.Helper()
+
+	_ = env.Load(".env.test.local")
+
+	project := env.GetStringOrDefault("GOOGLE_PROJECT", "")
+	if project == "" {
+		t.Skip("GOOGLE_PROJECT not set")
+	}
+
+	log := slog.New(slog.NewTextHandler(&tWriter{t}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	return google.NewClient(google.NewClientOptions{
+		Location: env.GetStringOrDefault("GOOGLE_LOCATION", "us-central1"),
+		Log:      log,
+		Project:  project,
+	})
+}
+
+// newVertexStandInClient for Vertex AI against a local stand-in at baseURL, with fake service account credentials
+// whose tokens come from another local stand-in.
+func newVertexStandInClient(t *testing.T, baseURL string) *google.Client {
+	t.Helper()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"test","token_type":"Bearer","expires_in":3600}`))
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	is.NotError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	is.NotError(t, err)
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "test@test.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokenServer.URL,
+	})
+	is.NotError(t, err)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	is.NotError(t, os.WriteFile(path, credentials, 0600))
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+
+	return google.NewClient(google.NewClientOptions{
+		BaseURL:  baseURL,
+		Location: "us-central1",
+		Project:  "test",
+	})
+}
+
 type tWriter struct {
 	t *testing.T
 }
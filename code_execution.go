@@ -0,0 +1,101 @@
+package google
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+const (
+	// MessagePartTypeExecutableCode is for code generated by the model and run with the code execution tool.
+	// Read it with [ExecutableCodeFromPart].
+	MessagePartTypeExecutableCode = gai.MessagePartType("executable_code")
+
+	// MessagePartTypeCodeExecutionResult is for the result of running [MessagePartTypeExecutableCode].
+	// Read it with [CodeExecutionResultFromPart].
+	MessagePartTypeCodeExecutionResult = gai.MessagePartType("code_execution_result")
+)
+
+// ExecutableCode generated by the model when the code execution tool is enabled.
+type ExecutableCode struct {
+	Language genai.Language `json:"language"`
+	Code     string         `json:"code"`
+}
+
+// CodeExecutionResult of running [ExecutableCode].
+type CodeExecutionResult struct {
+	Outcome genai.Outcome `json:"outcome"`
+	Output  string        `json:"output"`
+}
+
+// ExecutableCodePart creates a [gai.MessagePart] of type [MessagePartTypeExecutableCode].
+// The code is stored as JSON in [gai.MessagePart.Data].
+func ExecutableCodePart(code ExecutableCode) gai.MessagePart {
+	return jsonPart(MessagePartTypeExecutableCode, code)
+}
+
+// CodeExecutionResultPart creates a [gai.MessagePart] of type [MessagePartTypeCodeExecutionResult].
+// The result is stored as JSON in [gai.MessagePart.Data].
+func CodeExecutionResultPart(result CodeExecutionResult) gai.MessagePart {
+	return jsonPart(MessagePartTypeCodeExecutionResult, result)
+}
+
+// ExecutableCodeFromPart reads the [ExecutableCode] from a part of type [MessagePartTypeExecutableCode].
+func ExecutableCodeFromPart(part gai.MessagePart) (ExecutableCode, error) {
+	var code ExecutableCode
+	if err := readJSONPart(part, MessagePartTypeExecutableCode, &code); err != nil {
+		return ExecutableCode{}, err
+	}
+	return code, nil
+}
+
+// CodeExecutionResultFromPart reads the [CodeExecutionResult] from a part of type [MessagePartTypeCodeExecutionResult].
+func CodeExecutionResultFromPart(part gai.MessagePart) (CodeExecutionResult, error) {
+	var result CodeExecutionResult
+	if err := readJSONPart(part, MessagePartTypeCodeExecutionResult, &result); err != nil {
+		return CodeExecutionResult{}, err
+	}
+	return result, nil
+}
+
+func jsonPart(partType gai.MessagePartType, v any) gai.MessagePart {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic("error marshaling part: " + err.Error())
+	}
+	return gai.MessagePart{
+		Type:     partType,
+		Data:     bytes.NewReader(data),
+		MIMEType: "application/json",
+	}
+}
+
+// readJSONPart into v, rewinding the part data afterwards if possible,
+// so the part can still be sent back to the model in a later request.
+func readJSONPart(part gai.MessagePart, partType gai.MessagePartType, v any) error {
+	if part.Type != partType {
+		return fmt.Errorf("part type is %v, not %v", part.Type, partType)
+	}
+	if part.Data == nil {
+		return fmt.Errorf("part has no data")
+	}
+
+	data, err := io.ReadAll(part.Data)
+	if err != nil {
+		return fmt.Errorf("error reading part data: %w", err)
+	}
+	if seeker, ok := part.Data.(io.Seeker); ok {
+		if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("error rewinding part data: %w", err)
+		}
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error unmarshaling part data: %w", err)
+	}
+	return nil
+}
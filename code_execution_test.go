@@ -0,0 +1,48 @@
+package google_test
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestExecutableCodeFromPart(t *testing.T) {
+	t.Run("can read executable code from a part more than once", func(t *testing.T) {
+		part := google.ExecutableCodePart(google.ExecutableCode{
+			Language: genai.LanguagePython,
+			Code:     "print(1 + 2)",
+		})
+		is.Equal(t, google.MessagePartTypeExecutableCode, part.Type)
+
+		for range 2 {
+			code, err := google.ExecutableCodeFromPart(part)
+			is.NotError(t, err)
+			is.Equal(t, genai.LanguagePython, code.Language)
+			is.Equal(t, "print(1 + 2)", code.Code)
+		}
+	})
+
+	t.Run("errors on wrong part type", func(t *testing.T) {
+		_, err := google.ExecutableCodeFromPart(gai.TextMessagePart("print(1 + 2)"))
+		is.True(t, err != nil, "should error")
+	})
+}
+
+func TestCodeExecutionResultFromPart(t *testing.T) {
+	t.Run("can read a code execution result from a part", func(t *testing.T) {
+		part := google.CodeExecutionResultPart(google.CodeExecutionResult{
+			Outcome: genai.OutcomeOK,
+			Output:  "3\n",
+		})
+		is.Equal(t, google.MessagePartTypeCodeExecutionResult, part.Type)
+
+		result, err := google.CodeExecutionResultFromPart(part)
+		is.NotError(t, err)
+		is.Equal(t, genai.OutcomeOK, result.Outcome)
+		is.Equal(t, "3\n", result.Output)
+	})
+}
@@ -0,0 +1,104 @@
+package google
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+)
+
+// ContentCapture is the policy for recording prompt and completion content in spans and logs.
+type ContentCapture string
+
+const (
+	// ContentCaptureNone doesn't record any content. It's the default.
+	ContentCaptureNone = ContentCapture("none")
+
+	// ContentCaptureSystem records system prompts only.
+	ContentCaptureSystem = ContentCapture("system")
+
+	// ContentCaptureFull records system prompts, messages, tool call arguments, tool results, and completions.
+	ContentCaptureFull = ContentCapture("full")
+)
+
+// Redactor transforms content before it's recorded in spans or logs, for example removing personal data.
+type Redactor func(content string) string
+
+// RedactRegexp replaces all matches of the regular expression with the replacement,
+// which can refer to submatches like [regexp.Regexp.ReplaceAllString].
+func RedactRegexp(re *regexp.Regexp, replacement string) Redactor {
+	return func(content string) string {
+		return re.ReplaceAllString(content, replacement)
+	}
+}
+
+// piiPatterns for [RedactPII]. They're deliberately broad, preferring false positives over leaks.
+var piiPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`), "[EMAIL]"},
+	{regexp.MustCompile(`\b(?:\d[ \-]?){13,19}\b`), "[CARD]"},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), "[IP]"},
+	{regexp.MustCompile(`\+\d{1,3}(?:[ \-.]?\d{1,4}){2,5}\b`), "[PHONE]"},
+	{regexp.MustCompile(`\(?\b\d{3}\)?[ \-.]?\d{3}[ \-.]?\d{4}\b`), "[PHONE]"},
+}
+
+// RedactPII replaces email addresses, payment card numbers, IPv4 addresses, and phone numbers
+// (international numbers starting with "+", and North American numbers) with placeholders like "[EMAIL]".
+func RedactPII() Redactor {
+	return func(content string) string {
+		for _, p := range piiPatterns {
+			content = p.re.ReplaceAllString(content, p.replacement)
+		}
+		return content
+	}
+}
+
+// Truncate content to at most maxLength runes, marking truncated content with a trailing "…".
+func Truncate(maxLength int) Redactor {
+	return func(content string) string {
+		runes := []rune(content)
+		if len(runes) <= maxLength {
+			return content
+		}
+		return string(runes[:maxLength]) + "…"
+	}
+}
+
+// Hash content with SHA-256, so equal content can be correlated without recording it.
+// The result is like "sha256:2cf24dba…".
+func Hash() Redactor {
+	return func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// contentPolicy decides whether content is recorded, and redacts it if so.
+type contentPolicy struct {
+	capture   ContentCapture
+	redactors []Redactor
+}
+
+// system prompt content, redacted, and whether it may be recorded.
+func (p contentPolicy) system(content string) (string, bool) {
+	if p.capture != ContentCaptureSystem && p.capture != ContentCaptureFull {
+		return "", false
+	}
+	return p.redact(content), true
+}
+
+// full content like messages, tool call arguments, tool results, and completions, redacted, and whether it may be recorded.
+func (p contentPolicy) full(content string) (string, bool) {
+	if p.capture != ContentCaptureFull {
+		return "", false
+	}
+	return p.redact(content), true
+}
+
+func (p contentPolicy) redact(content string) string {
+	for _, r := range p.redactors {
+		content = r(content)
+	}
+	return content
+}
@@ -0,0 +1,127 @@
+package google_test
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestRedactors(t *testing.T) {
+	t.Run("RedactPII replaces emails, card numbers, IP addresses, and phone numbers", func(t *testing.T) {
+		redact := google.RedactPII()
+		is.Equal(t, "Mail [EMAIL] or call [PHONE].", redact("Mail me@example.com or call +45 12 34 56 78."))
+		is.Equal(t, "Card [CARD], from [IP].", redact("Card 4111 1111 1111 1111, from 192.168.1.1."))
+		is.Equal(t, "Call [PHONE] on 2025-01-01.", redact("Call (555) 123-4567 on 2025-01-01."))
+	})
+
+	t.Run("RedactRegexp replaces matches", func(t *testing.T) {
+		redact := google.RedactRegexp(regexp.MustCompile(`secret-\w+`), "[SECRET]")
+		is.Equal(t, "The key is [SECRET].", redact("The key is secret-abc123."))
+	})
+
+	t.Run("Truncate shortens content by runes", func(t *testing.T) {
+		redact := google.Truncate(3)
+		is.Equal(t, "Hi!", redact("Hi!"))
+		is.Equal(t, "æøå…", redact("æøåæøå"))
+	})
+
+	t.Run("Hash replaces content with its SHA-256 hash", func(t *testing.T) {
+		redact := google.Hash()
+		is.Equal(t, "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", redact("hello"))
+	})
+}
+
+func TestChatCompleter_ChatComplete_ContentCapture(t *testing.T) {
+	t.Run("records no content by default", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:        newChatServer(t, http.StatusOK, toolCallStream),
+			Key:            "test",
+			MessageEvents:  true,
+			TracerProvider: tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("My email is me@example.com.")},
+			System:   gai.Ptr("You are a secret agent."),
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+
+		attrs := attributeMap(spans[0].Attributes())
+		is.True(t, attrs["ai.has_system_prompt"].AsBool(), "should have system prompt")
+		_, ok := attrs["ai.system_prompt"]
+		is.True(t, !ok, "should not record system prompt")
+
+		for _, e := range messageEvents(spans[0]) {
+			content := attributeMap(e.Attributes)["gen_ai.event.content"].AsString()
+			is.True(t, !strings.Contains(content, "secret agent"), "should not record system prompt")
+			is.True(t, !strings.Contains(content, "example.com"), "should not record messages")
+			is.True(t, !strings.Contains(content, "Let me check"), "should not record completions")
+			is.True(t, !strings.Contains(content, "Copenhagen"), "should not record tool call args")
+		}
+	})
+
+	t.Run("records only the system prompt with system capture", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:        newChatServer(t, http.StatusOK, toolCallStream),
+			ContentCapture: google.ContentCaptureSystem,
+			Key:            "test",
+			MessageEvents:  true,
+			TracerProvider: tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("My email is me@example.com.")},
+			System:   gai.Ptr("You are a secret agent."),
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		is.Equal(t, "You are a secret agent.", attributeMap(spans[0].Attributes())["ai.system_prompt"].AsString())
+
+		events := messageEvents(spans[0])
+		is.Equal(t, 3, len(events))
+		is.Equal(t, `{"content":"You are a secret agent."}`, attributeMap(events[0].Attributes)["gen_ai.event.content"].AsString())
+		is.Equal(t, `{}`, attributeMap(events[1].Attributes)["gen_ai.event.content"].AsString())
+	})
+
+	t.Run("redacts full content in order", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:          newChatServer(t, http.StatusOK, toolCallStream),
+			ContentCapture:   google.ContentCaptureFull,
+			ContentRedactors: []google.Redactor{google.RedactPII(), google.Truncate(20)},
+			Key:              "test",
+			MessageEvents:    true,
+			TracerProvider:   tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("My email is me@example.com, what's yours?")},
+			System:   gai.Ptr("You are a secret agent, licensed to chat."),
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		is.Equal(t, "You are a secret age…", attributeMap(spans[0].Attributes())["ai.system_prompt"].AsString())
+
+		events := messageEvents(spans[0])
+		is.Equal(t, 3, len(events))
+		is.Equal(t, `{"content":"My email is [EMAIL],…"}`, attributeMap(events[1].Attributes)["gen_ai.event.content"].AsString())
+	})
+}
@@ -0,0 +1,27 @@
+package google
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Exported for tests of unexported helpers in package google_test.
+var (
+	ConvertUsage   = convertUsage
+	EstimateCost   = estimateCost
+	ParseTimestamp = parseTimestamp
+)
+
+// StreamStats wraps streamStats, so tests can record chunks at given times.
+type StreamStats struct {
+	stats streamStats
+}
+
+func (s *StreamStats) Chunk(at time.Time) bool {
+	return s.stats.chunk(at)
+}
+
+func (s *StreamStats) Attributes() []attribute.KeyValue {
+	return s.stats.attributes()
+}
@@ -0,0 +1,254 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+)
+
+type ImageEditModel string
+
+const (
+	ImageEditModelImagen3Capability = ImageEditModel("models/imagen-3.0-capability-001")
+	ImageEditModelImagen4Upscale    = ImageEditModel("models/imagen-4.0-upscale-preview")
+)
+
+// ImageEditor edits and upscales images with Imagen models.
+// Image editing is only supported on Vertex AI, see [NewClientOptions.Project].
+type ImageEditor struct {
+	Client       *genai.Client
+	log          *slog.Logger
+	model        ImageEditModel
+	tracer       trace.Tracer
+	upscaleModel ImageEditModel
+}
+
+type NewImageEditorOptions struct {
+	// Model for [ImageEditor.EditImage].
+	// Defaults to the default model of [ModelFamilyImageEdit], see [ModelSpecs].
+	Model ImageEditModel
+
+	// UpscaleModel for [ImageEditor.UpscaleImage].
+	// Defaults to the default model of [ModelFamilyImageUpscale], see [ModelSpecs].
+	UpscaleModel ImageEditModel
+}
+
+func (c *Client) NewImageEditor(opts NewImageEditorOptions) *ImageEditor {
+	if opts.Model == "" {
+		opts.Model = defaultModel[ImageEditModel](ModelFamilyImageEdit)
+	}
+	if opts.UpscaleModel == "" {
+		opts.UpscaleModel = defaultModel[ImageEditModel](ModelFamilyImageUpscale)
+	}
+
+	return &ImageEditor{
+		Client:       c.Client,
+		log:          c.log,
+		model:        opts.Model,
+		tracer:       c.tracer,
+		upscaleModel: opts.UpscaleModel,
+	}
+}
+
+// InputImage for editing, with the image data read from Data, like a [gai.MessagePartTypeData] part.
+type InputImage struct {
+	Data     io.Reader
+	MIMEType string
+}
+
+// EditImageRequest for [ImageEditor.EditImage].
+type EditImageRequest struct {
+	// Count is the number of edited images to generate. Defaults to the model default.
+	Count int
+
+	// Image to edit.
+	Image InputImage
+
+	// Mask of the area to edit, where white is edited and black is kept.
+	// If nil, the mask is generated automatically according to MaskMode.
+	Mask *InputImage
+
+	// MaskDilation of the mask, as a fraction of the image width, to smooth edges. Optional.
+	MaskDilation *float32
+
+	// MaskMode for generating the mask automatically when Mask is nil, for example
+	// [genai.MaskReferenceModeMaskModeBackground] to replace the background.
+	MaskMode genai.MaskReferenceMode
+
+	// Mode of editing, for example [genai.EditModeInpaintInsertion], [genai.EditModeInpaintRemoval],
+	// [genai.EditModeOutpaint], or [genai.EditModeBgswap].
+	Mode genai.EditMode
+
+	// NegativePrompt describes what to discourage in the edited images.
+	NegativePrompt string
+
+	Prompt string
+}
+
+// EditImageResponse from [ImageEditor.EditImage].
+type EditImageResponse struct {
+	Images []GeneratedImage
+}
+
+// EditImage for inpainting, outpainting, background replacement, and more.
+// It returns an error if the client doesn't use Vertex AI.
+func (e *ImageEditor) EditImage(ctx context.Context, req EditImageRequest) (EditImageResponse, error) {
+	ctx, span := e.tracer.Start(ctx, "google.edit_image",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(e.model)),
+			attribute.String("ai.edit_mode", string(req.Mode)),
+		),
+	)
+	defer span.End()
+
+	log := logWithTrace(ctx, e.log).With("model", e.model)
+
+	if !isVertexAI(e.Client) {
+		err := errors.New("image editing is only supported on Vertex AI")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image edit failed")
+		return EditImageResponse{}, err
+	}
+
+	image, err := readInputImage(req.Image)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image read failed")
+		return EditImageResponse{}, fmt.Errorf("error reading image: %w", err)
+	}
+
+	references := []genai.ReferenceImage{genai.NewRawReferenceImage(image, 0)}
+
+	maskConfig := &genai.MaskReferenceConfig{
+		MaskDilation: req.MaskDilation,
+		MaskMode:     req.MaskMode,
+	}
+	switch {
+	case req.Mask != nil:
+		mask, err := readInputImage(*req.Mask)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "mask read failed")
+			return EditImageResponse{}, fmt.Errorf("error reading mask: %w", err)
+		}
+		maskConfig.MaskMode = genai.MaskReferenceModeMaskModeUserProvided
+		references = append(references, genai.NewMaskReferenceImage(mask, 1, maskConfig))
+		span.SetAttributes(attribute.String("ai.mask_mode", string(maskConfig.MaskMode)))
+
+	case req.MaskMode != "":
+		references = append(references, genai.NewMaskReferenceImage(nil, 1, maskConfig))
+		span.SetAttributes(attribute.String("ai.mask_mode", string(maskConfig.MaskMode)))
+	}
+
+	config := genai.EditImageConfig{
+		EditMode:         req.Mode,
+		IncludeRAIReason: true,
+		NegativePrompt:   req.NegativePrompt,
+		NumberOfImages:   int32(req.Count),
+	}
+	if req.Count > 0 {
+		span.SetAttributes(attribute.Int("ai.image_count", req.Count))
+	}
+
+	log.Debug("Sending image edit request", "edit_mode", req.Mode, "image_count", req.Count, "reference_count", len(references))
+
+	res, err := e.Client.Models.EditImage(trackRetries(ctx), modelName(e.Client, e.model), req.Prompt, references, &config)
+	if err != nil {
+		log.Info("Image edit request failed", "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image edit failed")
+		return EditImageResponse{}, err
+	}
+
+	images := convertGeneratedImages(res.GeneratedImages, span)
+	log.Debug("Edited image", "image_count", len(images))
+
+	return EditImageResponse{Images: images}, nil
+}
+
+// UpscaleImageRequest for [ImageEditor.UpscaleImage].
+type UpscaleImageRequest struct {
+	// Factor to upscale by, "x2", "x3", or "x4".
+	Factor string
+
+	Image InputImage
+}
+
+// UpscaleImageResponse from [ImageEditor.UpscaleImage].
+type UpscaleImageResponse struct {
+	Image GeneratedImage
+}
+
+// UpscaleImage to a higher resolution.
+// It returns an error if the client doesn't use Vertex AI.
+func (e *ImageEditor) UpscaleImage(ctx context.Context, req UpscaleImageRequest) (UpscaleImageResponse, error) {
+	ctx, span := e.tracer.Start(ctx, "google.upscale_image",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(e.upscaleModel)),
+			attribute.String("ai.upscale_factor", req.Factor),
+		),
+	)
+	defer span.End()
+
+	log := logWithTrace(ctx, e.log).With("model", e.upscaleModel)
+
+	if !isVertexAI(e.Client) {
+		err := errors.New("image upscaling is only supported on Vertex AI")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image upscale failed")
+		return UpscaleImageResponse{}, err
+	}
+
+	image, err := readInputImage(req.Image)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image read failed")
+		return UpscaleImageResponse{}, fmt.Errorf("error reading image: %w", err)
+	}
+
+	config := genai.UpscaleImageConfig{
+		IncludeRAIReason: true,
+	}
+
+	log.Debug("Sending image upscale request", "factor", req.Factor)
+
+	res, err := e.Client.Models.UpscaleImage(trackRetries(ctx), modelName(e.Client, e.upscaleModel), image, req.Factor, &config)
+	if err != nil {
+		log.Info("Image upscale request failed", "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image upscale failed")
+		return UpscaleImageResponse{}, err
+	}
+
+	images := convertGeneratedImages(res.GeneratedImages, span)
+	if len(images) == 0 {
+		err := fmt.Errorf("no upscaled image in response")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "no upscaled image")
+		return UpscaleImageResponse{}, err
+	}
+
+	log.Debug("Upscaled image")
+
+	return UpscaleImageResponse{Image: images[0]}, nil
+}
+
+func readInputImage(image InputImage) (*genai.Image, error) {
+	data, err := io.ReadAll(image.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &genai.Image{
+		ImageBytes: data,
+		MIMEType:   image.MIMEType,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package google_test
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestImageEditor_EditImage(t *testing.T) {
+	t.Run("can replace the background of an image", func(t *testing.T) {
+		ie := newImageEditor(t)
+
+		res, err := ie.EditImage(t.Context(), google.EditImageRequest{
+			Count:    1,
+			Image:    google.InputImage{Data: bytes.NewReader(image), MIMEType: "image/jpeg"},
+			MaskMode: genai.MaskReferenceModeMaskModeBackground,
+			Mode:     genai.EditModeBgswap,
+			Prompt:   "A sunny beach.",
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, 1, len(res.Images))
+		is.Equal(t, "", res.Images[0].RAIFilteredReason)
+		is.True(t, len(res.Images[0].Data) > 0, "should have image data")
+	})
+
+	t.Run("errors if the client doesn't use Vertex AI", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+		ie := c.NewImageEditor(google.NewImageEditorOptions{})
+
+		_, err := ie.EditImage(t.Context(), google.EditImageRequest{
+			Image:  google.InputImage{Data: bytes.NewReader(image), MIMEType: "image/jpeg"},
+			Prompt: "A sunny beach.",
+		})
+		is.True(t, err != nil, "should error")
+		is.Equal(t, "image editing is only supported on Vertex AI", err.Error())
+	})
+}
+
+func TestImageEditor_UpscaleImage(t *testing.T) {
+	t.Run("can upscale an image", func(t *testing.T) {
+		ie := newImageEditor(t)
+
+		res, err := ie.UpscaleImage(t.Context(), google.UpscaleImageRequest{
+			Factor: "x2",
+			Image:  google.InputImage{Data: bytes.NewReader(image), MIMEType: "image/jpeg"},
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, "", res.Image.RAIFilteredReason)
+		is.True(t, len(res.Image.Data) > len(image), "should be bigger")
+	})
+
+	t.Run("errors if the client doesn't use Vertex AI", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+		ie := c.NewImageEditor(google.NewImageEditorOptions{})
+
+		_, err := ie.UpscaleImage(t.Context(), google.UpscaleImageRequest{
+			Factor: "x2",
+			Image:  google.InputImage{Data: bytes.NewReader(image), MIMEType: "image/jpeg"},
+		})
+		is.True(t, err != nil, "should error")
+		is.Equal(t, "image upscaling is only supported on Vertex AI", err.Error())
+	})
+}
+
+func newImageEditor(t *testing.T) *google.ImageEditor {
+	c := newVertexClient(t)
+	return c.NewImageEditor(google.NewImageEditorOptions{})
+}
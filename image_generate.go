@@ -0,0 +1,171 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+)
+
+type ImageGenerateModel string
+
+const (
+	ImageGenerateModelImagen4      = ImageGenerateModel("models/imagen-4.0-generate-001")
+	ImageGenerateModelImagen4Fast  = ImageGenerateModel("models/imagen-4.0-fast-generate-001")
+	ImageGenerateModelImagen4Ultra = ImageGenerateModel("models/imagen-4.0-ultra-generate-001")
+)
+
+// ImageGenerator generates images from text prompts with Imagen models.
+// Image generation is only supported on Vertex AI, see [NewClientOptions.Project].
+type ImageGenerator struct {
+	Client *genai.Client
+	log    *slog.Logger
+	model  ImageGenerateModel
+	tracer trace.Tracer
+}
+
+type NewImageGeneratorOptions struct {
+	// Model defaults to the default model of [ModelFamilyImage], see [ModelSpecs].
+	Model ImageGenerateModel
+}
+
+func (c *Client) NewImageGenerator(opts NewImageGeneratorOptions) *ImageGenerator {
+	if opts.Model == "" {
+		opts.Model = defaultModel[ImageGenerateModel](ModelFamilyImage)
+	}
+
+	return &ImageGenerator{
+		Client: c.Client,
+		log:    c.log,
+		model:  opts.Model,
+		tracer: c.tracer,
+	}
+}
+
+// GenerateImageRequest for [ImageGenerator.GenerateImage].
+type GenerateImageRequest struct {
+	// AspectRatio of the images, for example "1:1", "3:4", "4:3", "9:16", or "16:9". Defaults to "1:1".
+	AspectRatio string
+
+	// Count is the number of images to generate. Defaults to the model default, usually 4.
+	Count int
+
+	// NegativePrompt describes what to discourage in the images.
+	NegativePrompt string
+
+	// PersonGeneration controls whether people can be generated.
+	PersonGeneration genai.PersonGeneration
+
+	Prompt string
+
+	// SafetyFilterLevel for filtering out images.
+	SafetyFilterLevel genai.SafetyFilterLevel
+}
+
+// GenerateImageResponse from [ImageGenerator.GenerateImage].
+type GenerateImageResponse struct {
+	Images []GeneratedImage
+}
+
+// GeneratedImage is either an image, or the reason it was filtered out by responsible AI (RAI) filters.
+type GeneratedImage struct {
+	Data     []byte
+	MIMEType string
+
+	// EnhancedPrompt is the prompt used to generate the image, if the model rewrote it.
+	EnhancedPrompt string
+
+	// RAIFilteredReason is set if the image was filtered out, in which case Data is empty.
+	RAIFilteredReason string
+}
+
+// GenerateImage from the request prompt.
+// It returns an error if the client doesn't use Vertex AI.
+func (g *ImageGenerator) GenerateImage(ctx context.Context, req GenerateImageRequest) (GenerateImageResponse, error) {
+	ctx, span := g.tracer.Start(ctx, "google.generate_image",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(g.model)),
+		),
+	)
+	defer span.End()
+
+	log := logWithTrace(ctx, g.log).With("model", g.model)
+
+	if !isVertexAI(g.Client) {
+		err := errors.New("image generation is only supported on Vertex AI")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image generation failed")
+		return GenerateImageResponse{}, err
+	}
+
+	config := genai.GenerateImagesConfig{
+		AspectRatio:       req.AspectRatio,
+		IncludeRAIReason:  true,
+		NegativePrompt:    req.NegativePrompt,
+		NumberOfImages:    int32(req.Count),
+		PersonGeneration:  req.PersonGeneration,
+		SafetyFilterLevel: req.SafetyFilterLevel,
+	}
+	if req.AspectRatio != "" {
+		span.SetAttributes(attribute.String("ai.aspect_ratio", req.AspectRatio))
+	}
+	if req.Count > 0 {
+		span.SetAttributes(attribute.Int("ai.image_count", req.Count))
+	}
+	if req.NegativePrompt != "" {
+		span.SetAttributes(attribute.Bool("ai.has_negative_prompt", true))
+	}
+	if req.PersonGeneration != "" {
+		span.SetAttributes(attribute.String("ai.person_generation", string(req.PersonGeneration)))
+	}
+	if req.SafetyFilterLevel != "" {
+		span.SetAttributes(attribute.String("ai.safety_filter_level", string(req.SafetyFilterLevel)))
+	}
+
+	log.Debug("Sending image generation request", "image_count", req.Count)
+
+	res, err := g.Client.Models.GenerateImages(trackRetries(ctx), modelName(g.Client, g.model), req.Prompt, &config)
+	if err != nil {
+		log.Info("Image generation request failed", "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "image generation failed")
+		return GenerateImageResponse{}, err
+	}
+
+	images := convertGeneratedImages(res.GeneratedImages, span)
+	log.Debug("Generated images", "image_count", len(images))
+
+	return GenerateImageResponse{Images: images}, nil
+}
+
+// convertGeneratedImages from the SDK, recording generated and filtered image counts on the span.
+func convertGeneratedImages(generatedImages []*genai.GeneratedImage, span trace.Span) []GeneratedImage {
+	var images []GeneratedImage
+	var filtered int
+	for _, image := range generatedImages {
+		generated := GeneratedImage{
+			EnhancedPrompt:    image.EnhancedPrompt,
+			RAIFilteredReason: image.RAIFilteredReason,
+		}
+		if image.Image != nil {
+			generated.Data = image.Image.ImageBytes
+			generated.MIMEType = image.Image.MIMEType
+		}
+		if generated.RAIFilteredReason != "" {
+			filtered++
+		}
+		images = append(images, generated)
+	}
+
+	span.SetAttributes(
+		attribute.Int("ai.generated_image_count", len(images)-filtered),
+		attribute.Int("ai.filtered_image_count", filtered),
+	)
+
+	return images
+}
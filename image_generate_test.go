@@ -0,0 +1,48 @@
+package google_test
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestImageGenerator_GenerateImage(t *testing.T) {
+	t.Run("can generate images", func(t *testing.T) {
+		ig := newImageGenerator(t)
+
+		res, err := ig.GenerateImage(t.Context(), google.GenerateImageRequest{
+			AspectRatio:      "16:9",
+			Count:            2,
+			NegativePrompt:   "text",
+			PersonGeneration: genai.PersonGenerationDontAllow,
+			Prompt:           "A gopher sitting on a cloud, in watercolor style.",
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, 2, len(res.Images))
+		for _, image := range res.Images {
+			is.Equal(t, "", image.RAIFilteredReason)
+			is.Equal(t, "image/png", image.MIMEType)
+			is.True(t, len(image.Data) > 0, "should have image data")
+		}
+	})
+
+	t.Run("errors if the client doesn't use Vertex AI", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+		ig := c.NewImageGenerator(google.NewImageGeneratorOptions{})
+
+		_, err := ig.GenerateImage(t.Context(), google.GenerateImageRequest{Prompt: "A gopher."})
+		is.True(t, err != nil, "should error")
+		is.Equal(t, "image generation is only supported on Vertex AI", err.Error())
+	})
+}
+
+func newImageGenerator(t *testing.T) *google.ImageGenerator {
+	c := newVertexClient(t)
+	return c.NewImageGenerator(google.NewImageGeneratorOptions{
+		Model: google.ImageGenerateModelImagen4Fast,
+	})
+}
@@ -0,0 +1,569 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+
+	"maragu.dev/gai-google/internal/schema"
+)
+
+type LiveModel string
+
+const (
+	LiveModelGemini2_0FlashLive        = LiveModel("models/gemini-2.0-flash-live-001")
+	LiveModelGemini2_5FlashNativeAudio = LiveModel("models/gemini-2.5-flash-native-audio-preview-09-2025")
+)
+
+// LiveSession is a realtime, bidirectional session with the Gemini Live API.
+// Send input with the Send methods, which are safe to call concurrently, and receive output with [LiveSession.Receive].
+type LiveSession struct {
+	cancel            context.CancelFunc
+	closed            atomic.Bool
+	closeOnce         sync.Once
+	content           contentPolicy
+	ctx               context.Context
+	handleLock        sync.RWMutex
+	idGenerator       IDGenerator
+	log               *slog.Logger
+	resumptionHandle  string
+	session           *genai.Session
+	span              trace.Span
+	toolCallIDs       toolCallIDs
+	toolCalls         map[string]context.CancelFunc
+	toolCallsLock     sync.Mutex
+	toolCallsWG       sync.WaitGroup
+	toolResultEncoder ToolResultEncoder
+	tools             []gai.Tool
+	tracer            trace.Tracer
+	writeLock         sync.Mutex
+}
+
+type ConnectLiveOptions struct {
+	// IDGenerator for tool call IDs, used when the model doesn't provide them.
+	// Defaults to [RandomID].
+	IDGenerator IDGenerator
+
+	// InputTranscription enables transcription of the audio input, received as [LiveEventTypeInputTranscription].
+	InputTranscription bool
+
+	// Model defaults to the default model of [ModelFamilyLive], see [ModelSpecs].
+	Model LiveModel
+
+	// OutputTranscription enables transcription of the audio output, received as [LiveEventTypeOutputTranscription].
+	OutputTranscription bool
+
+	// ResponseModality is the modality the model responds with, either [genai.ModalityAudio] or [genai.ModalityText].
+	// Defaults to [genai.ModalityAudio].
+	ResponseModality genai.Modality
+
+	// ResumptionHandle from [LiveSession.ResumptionHandle] of a previous session, to resume it.
+	ResumptionHandle string
+
+	System *string
+
+	Temperature *gai.Temperature
+
+	// ToolResultEncoder encodes tool results into function responses.
+	// Defaults to [EncodeToolResultAsJSON].
+	ToolResultEncoder ToolResultEncoder
+
+	// Tools the model can call during the session. Tool calls for these are executed concurrently while receiving
+	// with [LiveSession.Receive], and the results are sent back automatically.
+	// If the model cancels a tool call, the context passed to [gai.Tool.Execute] is cancelled and the result is dropped.
+	// A panic in a tool is recovered and sent back as an error result.
+	Tools []gai.Tool
+
+	// Voice is the name of a prebuilt voice for audio output, for example "Kore" or "Puck". Optional.
+	Voice string
+}
+
+// ConnectLive opens a [LiveSession]. Close it with [LiveSession.Close] when done.
+func (c *Client) ConnectLive(ctx context.Context, opts ConnectLiveOptions) (*LiveSession, error) {
+	if opts.IDGenerator == nil {
+		opts.IDGenerator = RandomID
+	}
+	if opts.Model == "" {
+		opts.Model = defaultModel[LiveModel](ModelFamilyLive)
+	}
+	if opts.ResponseModality == "" {
+		opts.ResponseModality = genai.ModalityAudio
+	}
+	if opts.ToolResultEncoder == nil {
+		opts.ToolResultEncoder = EncodeToolResultAsJSON
+	}
+
+	// The span lasts for the whole session, until it's closed
+	spanCtx, span := c.tracer.Start(ctx, "google.live_session",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(opts.Model)),
+			attribute.String("ai.response_modality", string(opts.ResponseModality)),
+		),
+	)
+	log := logWithTrace(spanCtx, c.log).With("model", opts.Model)
+
+	config := genai.LiveConnectConfig{
+		ResponseModalities: []genai.Modality{opts.ResponseModality},
+		// Always enable session resumption, so the server sends resumption handles
+		SessionResumption: &genai.SessionResumptionConfig{Handle: opts.ResumptionHandle},
+	}
+	if opts.ResumptionHandle != "" {
+		span.SetAttributes(attribute.Bool("ai.resumed", true))
+	}
+	if opts.System != nil {
+		config.SystemInstruction = genai.NewContentFromText(*opts.System, genai.RoleUser)
+		span.SetAttributes(attribute.Bool("ai.has_system_prompt", true))
+		if content, ok := c.content.system(*opts.System); ok {
+			span.SetAttributes(attribute.String("ai.system_prompt", content))
+		}
+	}
+	if opts.Temperature != nil {
+		config.Temperature = gai.Ptr(float32(*opts.Temperature))
+		span.SetAttributes(attribute.Float64("ai.temperature", float64(*opts.Temperature)))
+	}
+	if opts.Voice != "" {
+		config.SpeechConfig = &genai.SpeechConfig{VoiceConfig: newVoiceConfig(opts.Voice)}
+		span.SetAttributes(attribute.String("ai.voice", opts.Voice))
+	}
+	if len(opts.Tools) > 0 {
+		tools, err := schema.ConvertTools(opts.Tools)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "tool conversion failed")
+			span.End()
+			log.Info("Live session tool conversion failed", "error", err)
+			return nil, fmt.Errorf("error converting tools: %w", err)
+		}
+		config.Tools = tools
+
+		var toolNames []string
+		for _, tool := range opts.Tools {
+			toolNames = append(toolNames, tool.Name)
+		}
+		sort.Strings(toolNames)
+		span.SetAttributes(
+			attribute.Int("ai.tool_count", len(opts.Tools)),
+			attribute.StringSlice("ai.tools", toolNames),
+		)
+	}
+	if opts.InputTranscription {
+		config.InputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+	if opts.OutputTranscription {
+		config.OutputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+
+	log.Debug("Connecting live session", "tool_count", len(opts.Tools), "resumed", opts.ResumptionHandle != "")
+
+	session, err := c.Client.Live.Connect(ctx, modelName(c.Client, opts.Model), &config)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "live connect failed")
+		span.End()
+		log.Info("Live session connect failed", "error", err)
+		return nil, fmt.Errorf("error connecting: %w", err)
+	}
+
+	log.Debug("Live session connected")
+
+	// Tool calls run in the context of the session span, until the session is closed
+	sessionCtx, cancel := context.WithCancel(context.WithoutCancel(spanCtx))
+
+	return &LiveSession{
+		cancel:            cancel,
+		content:           c.content,
+		ctx:               sessionCtx,
+		idGenerator:       opts.IDGenerator,
+		log:               log,
+		resumptionHandle:  opts.ResumptionHandle,
+		session:           session,
+		span:              span,
+		toolCallIDs:       toolCallIDs{},
+		toolCalls:         map[string]context.CancelFunc{},
+		toolResultEncoder: opts.ToolResultEncoder,
+		tools:             opts.Tools,
+		tracer:            c.tracer,
+	}, nil
+}
+
+type LiveEventType string
+
+const (
+	// LiveEventTypeAudio has audio output in [LiveEvent.Data], usually 16-bit PCM at 24 kHz.
+	LiveEventTypeAudio = LiveEventType("audio")
+
+	// LiveEventTypeGoAway means the server will close the connection after [LiveEvent.TimeLeft].
+	// Resume in a new session with [ConnectLiveOptions.ResumptionHandle].
+	LiveEventTypeGoAway = LiveEventType("go_away")
+
+	// LiveEventTypeInputTranscription has a transcription of the audio input in [LiveEvent.Text].
+	LiveEventTypeInputTranscription = LiveEventType("input_transcription")
+
+	// LiveEventTypeInterrupted means the user interrupted the model, so any buffered audio output should be discarded.
+	LiveEventTypeInterrupted = LiveEventType("interrupted")
+
+	// LiveEventTypeOutputTranscription has a transcription of the audio output in [LiveEvent.Text].
+	LiveEventTypeOutputTranscription = LiveEventType("output_transcription")
+
+	// LiveEventTypeText has text output in [LiveEvent.Text].
+	LiveEventTypeText = LiveEventType("text")
+
+	// LiveEventTypeToolCall has a tool call in [LiveEvent.ToolCall]. Respond with [LiveSession.SendToolResults],
+	// unless the tool is in [ConnectLiveOptions.Tools], in which case it's already being executed.
+	LiveEventTypeToolCall = LiveEventType("tool_call")
+
+	// LiveEventTypeToolCallCancellation has the IDs of previous tool calls the model retracted in [LiveEvent.ToolCallIDs].
+	LiveEventTypeToolCallCancellation = LiveEventType("tool_call_cancellation")
+
+	// LiveEventTypeTurnComplete means the model is done with its turn.
+	LiveEventTypeTurnComplete = LiveEventType("turn_complete")
+
+	// LiveEventTypeUsage has token usage in [LiveEvent.Usage].
+	LiveEventTypeUsage = LiveEventType("usage")
+)
+
+// LiveEvent received in a [LiveSession]. Which fields are set depends on the Type.
+type LiveEvent struct {
+	Type LiveEventType
+
+	Data     []byte
+	MIMEType string
+
+	Text string
+
+	TimeLeft time.Duration
+
+	ToolCall    gai.ToolCall
+	ToolCallIDs []string
+
+	Usage gai.ChatCompleteResponseUsage
+}
+
+// Receive events from the session until it's closed.
+// Only one goroutine should receive at a time, but sending concurrently is fine.
+// The iteration ends without an error when the session is closed with [LiveSession.Close] or by the server.
+func (s *LiveSession) Receive() iter.Seq2[LiveEvent, error] {
+	return func(yield func(LiveEvent, error) bool) {
+		for {
+			msg, err := s.session.Receive()
+			if err != nil {
+				if s.closed.Load() || websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return
+				}
+				s.span.RecordError(err)
+				s.span.SetStatus(codes.Error, "live receive failed")
+				s.log.Info("Live session receive failed", "error", err)
+				yield(LiveEvent{}, fmt.Errorf("error receiving: %w", err))
+				return
+			}
+
+			events, err := s.convertMessage(msg)
+			if err != nil {
+				s.span.RecordError(err)
+				s.span.SetStatus(codes.Error, "live message conversion failed")
+				s.log.Info("Live session message conversion failed", "error", err)
+				yield(LiveEvent{}, err)
+				return
+			}
+
+			for _, event := range events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// convertMessage from the server into events, keeping track of the session state.
+func (s *LiveSession) convertMessage(msg *genai.LiveServerMessage) ([]LiveEvent, error) {
+	var events []LiveEvent
+
+	if content := msg.ServerContent; content != nil {
+		if content.InputTranscription != nil && content.InputTranscription.Text != "" {
+			events = append(events, LiveEvent{Type: LiveEventTypeInputTranscription, Text: content.InputTranscription.Text})
+		}
+
+		if content.ModelTurn != nil {
+			for _, part := range content.ModelTurn.Parts {
+				switch {
+				case part.InlineData != nil:
+					events = append(events, LiveEvent{Type: LiveEventTypeAudio, Data: part.InlineData.Data, MIMEType: part.InlineData.MIMEType})
+				case part.Text != "" && !part.Thought:
+					events = append(events, LiveEvent{Type: LiveEventTypeText, Text: part.Text})
+				}
+			}
+		}
+
+		if content.OutputTranscription != nil && content.OutputTranscription.Text != "" {
+			events = append(events, LiveEvent{Type: LiveEventTypeOutputTranscription, Text: content.OutputTranscription.Text})
+		}
+
+		if content.Interrupted {
+			s.span.AddEvent("interrupted")
+			events = append(events, LiveEvent{Type: LiveEventTypeInterrupted})
+		}
+
+		if content.TurnComplete {
+			s.span.AddEvent("turn_complete")
+			events = append(events, LiveEvent{Type: LiveEventTypeTurnComplete})
+		}
+	}
+
+	if msg.ToolCall != nil {
+		for _, call := range msg.ToolCall.FunctionCalls {
+			id, err := s.toolCallIDs.unique(call.ID, s.idGenerator)
+			if err != nil {
+				return nil, fmt.Errorf("error generating tool call ID: %w", err)
+			}
+			args, err := json.Marshal(call.Args)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling tool call args: %w", err)
+			}
+			toolCall := gai.ToolCall{
+				ID:   id,
+				Name: call.Name,
+				Args: args,
+			}
+			if tool := s.findTool(call.Name); tool != nil {
+				s.executeToolCall(*tool, toolCall)
+			}
+			events = append(events, LiveEvent{Type: LiveEventTypeToolCall, ToolCall: toolCall})
+		}
+	}
+
+	if msg.ToolCallCancellation != nil {
+		for _, id := range msg.ToolCallCancellation.IDs {
+			s.cancelToolCall(id)
+		}
+		events = append(events, LiveEvent{Type: LiveEventTypeToolCallCancellation, ToolCallIDs: msg.ToolCallCancellation.IDs})
+	}
+
+	if update := msg.SessionResumptionUpdate; update != nil && update.Resumable && update.NewHandle != "" {
+		s.handleLock.Lock()
+		s.resumptionHandle = update.NewHandle
+		s.handleLock.Unlock()
+	}
+
+	if msg.GoAway != nil {
+		s.span.AddEvent("go_away", trace.WithAttributes(attribute.String("ai.time_left", msg.GoAway.TimeLeft.String())))
+		s.log.Info("Live session going away", "time_left", msg.GoAway.TimeLeft)
+		events = append(events, LiveEvent{Type: LiveEventTypeGoAway, TimeLeft: msg.GoAway.TimeLeft})
+	}
+
+	if usage := msg.UsageMetadata; usage != nil {
+		events = append(events, LiveEvent{
+			Type: LiveEventTypeUsage,
+			Usage: gai.ChatCompleteResponseUsage{
+				PromptTokens:     int(usage.PromptTokenCount),
+				ThoughtsTokens:   int(usage.ThoughtsTokenCount),
+				CompletionTokens: int(usage.ResponseTokenCount),
+			},
+		})
+	}
+
+	return events, nil
+}
+
+func (s *LiveSession) findTool(name string) *gai.Tool {
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			return &s.tools[i]
+		}
+	}
+	return nil
+}
+
+// executeToolCall in a new goroutine, and send the result unless the call is cancelled in the meantime.
+// Tool calls received after the session is closed are not executed.
+func (s *LiveSession) executeToolCall(tool gai.Tool, toolCall gai.ToolCall) {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	// Adding to the wait group under the same lock as Close sets the closed state, so it never races with Wait
+	s.toolCallsLock.Lock()
+	if s.closed.Load() {
+		s.toolCallsLock.Unlock()
+		cancel()
+		return
+	}
+	s.toolCalls[toolCall.ID] = cancel
+	s.toolCallsWG.Add(1)
+	s.toolCallsLock.Unlock()
+
+	go func() {
+		defer s.toolCallsWG.Done()
+		defer cancel()
+
+		ctx, span := s.tracer.Start(ctx, "google.live_tool_call",
+			trace.WithAttributes(
+				attribute.String("ai.tool_name", toolCall.Name),
+				attribute.String("ai.tool_call_id", toolCall.ID),
+			),
+		)
+		defer span.End()
+
+		log := logWithTrace(ctx, s.log).With("tool_name", toolCall.Name, "tool_call_id", toolCall.ID)
+		if args, ok := s.content.full(string(toolCall.Args)); ok {
+			span.SetAttributes(attribute.String("ai.tool_args", args))
+			log = log.With("tool_args", args)
+		}
+		log.Debug("Executing tool call")
+
+		start := time.Now()
+		result := gai.ToolResult{
+			ID:   toolCall.ID,
+			Name: toolCall.Name,
+		}
+		result.Content, result.Err = executeTool(ctx, tool, toolCall.Args)
+		if result.Err != nil {
+			span.RecordError(result.Err)
+			span.SetStatus(codes.Error, "tool execution failed")
+			log.Info("Tool call failed", "error", result.Err, "duration", time.Since(start))
+		} else {
+			if content, ok := s.content.full(result.Content); ok {
+				span.SetAttributes(attribute.String("ai.tool_result", content))
+				log = log.With("tool_result", content)
+			}
+			log.Debug("Tool call finished", "duration", time.Since(start))
+		}
+
+		s.toolCallsLock.Lock()
+		_, ok := s.toolCalls[toolCall.ID]
+		delete(s.toolCalls, toolCall.ID)
+		s.toolCallsLock.Unlock()
+
+		if !ok {
+			span.SetAttributes(attribute.Bool("ai.tool_call_cancelled", true))
+			log.Debug("Tool call cancelled, not sending result")
+			return
+		}
+
+		if err := s.SendToolResults(result); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "tool result send failed")
+			log.Info("Tool result send failed", "error", err)
+		}
+	}()
+}
+
+// executeTool, recovering a panic in the tool into an error.
+func executeTool(ctx context.Context, tool gai.Tool, args json.RawMessage) (content string, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			content, err = "", fmt.Errorf("tool %v panicked: %v", tool.Name, rec)
+		}
+	}()
+
+	return tool.Execute(ctx, args)
+}
+
+// cancelToolCall if it's still running, so its result isn't sent.
+func (s *LiveSession) cancelToolCall(id string) {
+	s.toolCallsLock.Lock()
+	defer s.toolCallsLock.Unlock()
+
+	if cancel, ok := s.toolCalls[id]; ok {
+		delete(s.toolCalls, id)
+		cancel()
+		s.log.Debug("Cancelling tool call", "tool_call_id", id)
+	}
+}
+
+// ResumptionHandle is the latest handle to resume the session with, after the connection is closed.
+// See [ConnectLiveOptions.ResumptionHandle]. It's empty if the session can't be resumed (yet).
+func (s *LiveSession) ResumptionHandle() string {
+	s.handleLock.RLock()
+	defer s.handleLock.RUnlock()
+	return s.resumptionHandle
+}
+
+// SendText to the model as realtime input.
+func (s *LiveSession) SendText(text string) error {
+	return s.sendRealtimeInput(genai.LiveRealtimeInput{Text: text})
+}
+
+// SendAudio to the model, for example a chunk of microphone audio with MIME type "audio/pcm;rate=16000".
+// Audio input must be 16-bit little-endian PCM.
+func (s *LiveSession) SendAudio(data []byte, mimeType string) error {
+	return s.sendRealtimeInput(genai.LiveRealtimeInput{Audio: &genai.Blob{Data: data, MIMEType: mimeType}})
+}
+
+// SendVideo frame to the model, for example a JPEG image from a camera.
+func (s *LiveSession) SendVideo(data []byte, mimeType string) error {
+	return s.sendRealtimeInput(genai.LiveRealtimeInput{Video: &genai.Blob{Data: data, MIMEType: mimeType}})
+}
+
+// EndAudio tells the model the audio stream is paused, for example because the microphone was turned off.
+func (s *LiveSession) EndAudio() error {
+	return s.sendRealtimeInput(genai.LiveRealtimeInput{AudioStreamEnd: true})
+}
+
+func (s *LiveSession) sendRealtimeInput(input genai.LiveRealtimeInput) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if err := s.session.SendRealtimeInput(input); err != nil {
+		return fmt.Errorf("error sending realtime input: %w", err)
+	}
+	return nil
+}
+
+// SendToolResults for tool calls received as [LiveEventTypeToolCall].
+func (s *LiveSession) SendToolResults(results ...gai.ToolResult) error {
+	var responses []*genai.FunctionResponse
+	for _, result := range results {
+		response, err := s.toolResultEncoder(result)
+		if err != nil {
+			return fmt.Errorf("error encoding tool result: %w", err)
+		}
+		responses = append(responses, &genai.FunctionResponse{
+			ID:       result.ID,
+			Name:     result.Name,
+			Response: response,
+		})
+	}
+
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if err := s.session.SendToolResponse(genai.LiveToolResponseInput{FunctionResponses: responses}); err != nil {
+		return fmt.Errorf("error sending tool response: %w", err)
+	}
+	return nil
+}
+
+// Close the session, cancelling any running tool calls and waiting for them to return. It's safe to call more than once.
+func (s *LiveSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		s.toolCallsLock.Lock()
+		s.closed.Store(true)
+		s.toolCallsLock.Unlock()
+
+		s.cancel()
+		err = s.session.Close()
+		if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+			s.span.RecordError(err)
+			s.log.Info("Live session close failed", "error", err)
+		}
+		s.toolCallsWG.Wait()
+		s.span.End()
+		s.log.Debug("Live session closed")
+	})
+	return err
+}
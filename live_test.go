@@ -0,0 +1,410 @@
+package google_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestClient_ConnectLive(t *testing.T) {
+	t.Run("can send text and receive text", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			setup := conn.setup()
+			is.Equal(t, "models/gemini-2.0-flash-live-001", setup["model"])
+
+			msg := conn.read()
+			is.Equal(t, "Hi!", msg["realtimeInput"].(map[string]any)["text"])
+
+			conn.write(`{"serverContent":{"modelTurn":{"parts":[{"text":"Hello!"}]}}}`)
+			conn.write(`{"serverContent":{"turnComplete":true}}`)
+			conn.write(`{"usageMetadata":{"promptTokenCount":2,"responseTokenCount":3}}`)
+		})
+
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{
+			Model:            google.LiveModelGemini2_0FlashLive,
+			ResponseModality: "TEXT",
+		})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		err = s.SendText("Hi!")
+		is.NotError(t, err)
+
+		events := receiveUntil(t, s, google.LiveEventTypeUsage)
+		is.Equal(t, 3, len(events))
+		is.Equal(t, google.LiveEventTypeText, events[0].Type)
+		is.Equal(t, "Hello!", events[0].Text)
+		is.Equal(t, google.LiveEventTypeTurnComplete, events[1].Type)
+		is.Equal(t, 2, events[2].Usage.PromptTokens)
+		is.Equal(t, 3, events[2].Usage.CompletionTokens)
+	})
+
+	t.Run("can send audio and video and receive audio, transcriptions, and interruptions", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			setup := conn.setup()
+			config := setup["generationConfig"].(map[string]any)
+			is.Equal(t, "AUDIO", config["responseModalities"].([]any)[0])
+			is.True(t, setup["inputAudioTranscription"] != nil, "should have input transcription")
+
+			msg := conn.read()
+			audio := msg["realtimeInput"].(map[string]any)["audio"].(map[string]any)
+			is.Equal(t, "audio/pcm;rate=16000", audio["mimeType"])
+			is.Equal(t, "AQI=", audio["data"])
+
+			msg = conn.read()
+			video := msg["realtimeInput"].(map[string]any)["video"].(map[string]any)
+			is.Equal(t, "image/jpeg", video["mimeType"])
+
+			msg = conn.read()
+			is.Equal(t, true, msg["realtimeInput"].(map[string]any)["audioStreamEnd"])
+
+			conn.write(`{"serverContent":{"inputTranscription":{"text":"Hi"}}}`)
+			conn.write(`{"serverContent":{"modelTurn":{"parts":[{"inlineData":{"mimeType":"audio/pcm;rate=24000","data":"AwQ="}}]},"outputTranscription":{"text":"Hel"}}}`)
+			conn.write(`{"serverContent":{"interrupted":true}}`)
+		})
+
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{
+			InputTranscription:  true,
+			Model:               google.LiveModelGemini2_0FlashLive,
+			OutputTranscription: true,
+		})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		is.NotError(t, s.SendAudio([]byte{1, 2}, "audio/pcm;rate=16000"))
+		is.NotError(t, s.SendVideo(image, "image/jpeg"))
+		is.NotError(t, s.EndAudio())
+
+		events := receiveUntil(t, s, google.LiveEventTypeInterrupted)
+		is.Equal(t, 4, len(events))
+		is.Equal(t, google.LiveEventTypeInputTranscription, events[0].Type)
+		is.Equal(t, "Hi", events[0].Text)
+		is.Equal(t, google.LiveEventTypeAudio, events[1].Type)
+		is.EqualSlice(t, []byte{3, 4}, events[1].Data)
+		is.Equal(t, "audio/pcm;rate=24000", events[1].MIMEType)
+		is.Equal(t, google.LiveEventTypeOutputTranscription, events[2].Type)
+		is.Equal(t, "Hel", events[2].Text)
+	})
+
+	t.Run("can resume a session with the latest resumption handle", func(t *testing.T) {
+		var connections atomic.Int32
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			setup := conn.setup()
+			resumption := setup["sessionResumption"].(map[string]any)
+
+			if connections.Add(1) == 1 {
+				is.Equal(t, nil, resumption["handle"])
+				conn.write(`{"sessionResumptionUpdate":{"newHandle":"handle1","resumable":true}}`)
+				conn.write(`{"sessionResumptionUpdate":{"resumable":false}}`)
+				conn.write(`{"goAway":{"timeLeft":"5s"}}`)
+				return
+			}
+
+			is.Equal(t, "handle1", resumption["handle"])
+			conn.write(`{"serverContent":{"turnComplete":true}}`)
+		})
+
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{Model: google.LiveModelGemini2_0FlashLive})
+		is.NotError(t, err)
+		is.Equal(t, "", s.ResumptionHandle())
+
+		events := receiveUntil(t, s, google.LiveEventTypeGoAway)
+		is.Equal(t, 5*time.Second, events[0].TimeLeft)
+		is.Equal(t, "handle1", s.ResumptionHandle())
+		is.NotError(t, s.Close())
+
+		s, err = c.ConnectLive(t.Context(), google.ConnectLiveOptions{
+			Model:            google.LiveModelGemini2_0FlashLive,
+			ResumptionHandle: s.ResumptionHandle(),
+		})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		receiveUntil(t, s, google.LiveEventTypeTurnComplete)
+	})
+
+	t.Run("can receive tool calls and send tool results", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			conn.setup()
+
+			conn.write(`{"toolCall":{"functionCalls":[{"id":"call1","name":"get_weather","args":{"city":"Copenhagen"}}]}}`)
+			conn.write(`{"toolCallCancellation":{"ids":["call0"]}}`)
+
+			msg := conn.read()
+			responses := msg["toolResponse"].(map[string]any)["functionResponses"].([]any)
+			is.Equal(t, 1, len(responses))
+			response := responses[0].(map[string]any)
+			is.Equal(t, "call1", response["id"])
+			is.Equal(t, "get_weather", response["name"])
+			is.Equal(t, "sunny", response["response"].(map[string]any)["output"])
+
+			conn.write(`{"serverContent":{"turnComplete":true}}`)
+		})
+
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{Model: google.LiveModelGemini2_0FlashLive})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		events := receiveUntil(t, s, google.LiveEventTypeToolCallCancellation)
+		is.Equal(t, google.LiveEventTypeToolCall, events[0].Type)
+		is.Equal(t, "call1", events[0].ToolCall.ID)
+		is.Equal(t, "get_weather", events[0].ToolCall.Name)
+		is.Equal(t, `{"city":"Copenhagen"}`, string(events[0].ToolCall.Args))
+		is.EqualSlice(t, []string{"call0"}, events[1].ToolCallIDs)
+
+		err = s.SendToolResults(gai.ToolResult{ID: "call1", Name: "get_weather", Content: `"sunny"`})
+		is.NotError(t, err)
+
+		receiveUntil(t, s, google.LiveEventTypeTurnComplete)
+	})
+
+	t.Run("ends receiving without error when closed", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			conn.setup()
+			_, _, _ = conn.ReadMessage()
+		})
+
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{Model: google.LiveModelGemini2_0FlashLive})
+		is.NotError(t, err)
+
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_ = s.Close()
+		}()
+
+		for _, err := range s.Receive() {
+			is.NotError(t, err)
+		}
+	})
+
+	t.Run("executes tools concurrently and sends the results", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			setup := conn.setup()
+			declarations := setup["tools"].([]any)[0].(map[string]any)["functionDeclarations"].([]any)
+			is.Equal(t, "wait_for", declarations[0].(map[string]any)["name"])
+
+			conn.write(`{"toolCall":{"functionCalls":[{"id":"call1","name":"wait_for","args":{"name":"b"}},{"id":"call2","name":"wait_for","args":{"name":""}}]}}`)
+
+			// call1 waits for call2, so call2's result arrives first
+			var ids []string
+			for range 2 {
+				msg := conn.read()
+				responses := msg["toolResponse"].(map[string]any)["functionResponses"].([]any)
+				for _, response := range responses {
+					ids = append(ids, response.(map[string]any)["id"].(string))
+				}
+			}
+			is.EqualSlice(t, []string{"call2", "call1"}, ids)
+
+			conn.write(`{"serverContent":{"turnComplete":true}}`)
+		})
+
+		done := make(chan struct{})
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{
+			Model: google.LiveModelGemini2_0FlashLive,
+			Tools: []gai.Tool{newWaitForTool(done, nil)},
+		})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		events := receiveUntil(t, s, google.LiveEventTypeTurnComplete)
+		is.Equal(t, google.LiveEventTypeToolCall, events[0].Type)
+		is.Equal(t, "call1", events[0].ToolCall.ID)
+		is.Equal(t, "call2", events[1].ToolCall.ID)
+	})
+
+	t.Run("sends tool panics as errors", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			conn.setup()
+
+			conn.write(`{"toolCall":{"functionCalls":[{"id":"call1","name":"panic","args":{}}]}}`)
+
+			msg := conn.read()
+			response := msg["toolResponse"].(map[string]any)["functionResponses"].([]any)[0].(map[string]any)
+			is.Equal(t, "call1", response["id"])
+			is.Equal(t, "tool panic panicked: oh no", response["response"].(map[string]any)["error"])
+
+			conn.write(`{"serverContent":{"turnComplete":true}}`)
+		})
+
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{
+			Model: google.LiveModelGemini2_0FlashLive,
+			Tools: []gai.Tool{{
+				Name:   "panic",
+				Schema: gai.GenerateToolSchema[struct{}](),
+				Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+					panic("oh no")
+				},
+			}},
+		})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		receiveUntil(t, s, google.LiveEventTypeTurnComplete)
+	})
+
+	t.Run("cancels tool calls retracted by the model and drops their results", func(t *testing.T) {
+		c := newLiveServer(t, func(t handlerT, conn *liveConn) {
+			conn.setup()
+
+			conn.write(`{"toolCall":{"functionCalls":[{"id":"call1","name":"wait_for","args":{"name":"never"}}]}}`)
+			conn.write(`{"toolCallCancellation":{"ids":["call1"]}}`)
+
+			// The next message is the text, not a tool response
+			msg := conn.read()
+			is.Equal(t, "Never mind.", msg["realtimeInput"].(map[string]any)["text"])
+
+			conn.write(`{"serverContent":{"turnComplete":true}}`)
+		})
+
+		cancelled := make(chan struct{})
+		s, err := c.ConnectLive(t.Context(), google.ConnectLiveOptions{
+			Model: google.LiveModelGemini2_0FlashLive,
+			Tools: []gai.Tool{newWaitForTool(nil, cancelled)},
+		})
+		is.NotError(t, err)
+		defer func() { _ = s.Close() }()
+
+		receiveUntil(t, s, google.LiveEventTypeToolCallCancellation)
+		<-cancelled
+
+		is.NotError(t, s.SendText("Never mind."))
+
+		receiveUntil(t, s, google.LiveEventTypeTurnComplete)
+	})
+}
+
+type waitForArgs struct {
+	Name string `json:"name" jsonschema_description:"Name of the call to wait for, or empty to not wait."`
+}
+
+// newWaitForTool that waits for the done channel to be closed if a name is given, and closes it otherwise.
+// If its context is cancelled while waiting, it closes the cancelled channel.
+func newWaitForTool(done, cancelled chan struct{}) gai.Tool {
+	return gai.Tool{
+		Name:        "wait_for",
+		Description: "Wait for another call.",
+		Schema:      gai.GenerateToolSchema[waitForArgs](),
+		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			var args waitForArgs
+			if err := json.Unmarshal(rawArgs, &args); err != nil {
+				return "", err
+			}
+
+			if args.Name == "" {
+				close(done)
+				return "done", nil
+			}
+
+			select {
+			case <-ctx.Done():
+				close(cancelled)
+				return "", ctx.Err()
+			case <-done:
+				return "waited", nil
+			}
+		},
+	}
+}
+
+// receiveUntil the event type is received, returning all events received, including the last one.
+func receiveUntil(t *testing.T, s *google.LiveSession, typ google.LiveEventType) []google.LiveEvent {
+	t.Helper()
+
+	var events []google.LiveEvent
+	for event, err := range s.Receive() {
+		is.NotError(t, err)
+		events = append(events, event)
+		if event.Type == typ {
+			return events
+		}
+	}
+	t.Fatal("session ended before event type", typ)
+	return nil
+}
+
+// handlerT is for checks in stand-in handlers, which don't run on the test goroutine, so they must not call
+// [testing.T.FailNow]. Failing marks the test as failed and aborts the handler instead.
+type handlerT struct {
+	*testing.T
+}
+
+func (t handlerT) FailNow() {
+	t.Fail()
+	panic(http.ErrAbortHandler)
+}
+
+type liveConn struct {
+	*websocket.Conn
+	t handlerT
+}
+
+// setup message from the client, which is answered with setup complete.
+func (c *liveConn) setup() map[string]any {
+	c.t.Helper()
+
+	msg := c.read()
+	setup, ok := msg["setup"].(map[string]any)
+	is.True(c.t, ok, "should be setup message")
+	c.write(`{"setupComplete":{}}`)
+	return setup
+}
+
+func (c *liveConn) read() map[string]any {
+	c.t.Helper()
+
+	_, data, err := c.ReadMessage()
+	is.NotError(c.t, err)
+	var msg map[string]any
+	is.NotError(c.t, json.Unmarshal(data, &msg))
+	return msg
+}
+
+func (c *liveConn) write(msg string) {
+	c.t.Helper()
+
+	is.NotError(c.t, c.WriteMessage(websocket.TextMessage, []byte(msg)))
+}
+
+// newLiveServer is a local stand-in for the Live API, calling handle for each connection on the server goroutine.
+func newLiveServer(t *testing.T, handle func(t handlerT, conn *liveConn)) *google.Client {
+	t.Helper()
+
+	var upgrader websocket.Upgrader
+	var handlers sync.WaitGroup
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers.Add(1)
+		defer handlers.Done()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+
+		handle(handlerT{t}, &liveConn{Conn: conn, t: handlerT{t}})
+	}))
+	// The server doesn't track hijacked connections, so wait for the handlers to be done with the test
+	t.Cleanup(func() {
+		server.Close()
+		handlers.Wait()
+	})
+
+	return google.NewClient(google.NewClientOptions{
+		BaseURL: strings.Replace(server.URL, "http://", "ws://", 1),
+		Key:     "test",
+	})
+}
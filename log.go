@@ -0,0 +1,18 @@
+package google
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// logWithTrace returns the logger with the trace and span IDs of the span in the context, if any,
+// so logs can be correlated with traces.
+func logWithTrace(ctx context.Context, log *slog.Logger) *slog.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return log
+	}
+	return log.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+}
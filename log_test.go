@@ -0,0 +1,188 @@
+package google_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestChatCompleter_ChatComplete_Logging(t *testing.T) {
+	t.Run("logs the request and stream lifecycle with trace and span IDs", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+		var buf bytes.Buffer
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:        newChatServer(t, http.StatusOK, toolCallStream),
+			Key:            "test",
+			Log:            slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			TracerProvider: tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("What's the weather in Copenhagen?")},
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		traceID := spans[0].SpanContext().TraceID().String()
+		spanID := spans[0].SpanContext().SpanID().String()
+
+		logs := decodeLogs(t, &buf)
+		var messages []string
+		for _, l := range logs {
+			messages = append(messages, l["msg"].(string))
+			is.Equal(t, any(traceID), l["trace_id"])
+			is.Equal(t, any(spanID), l["span_id"])
+			is.Equal(t, any(string(google.ChatCompleteModelGemini2_5Flash)), l["model"])
+		}
+		is.EqualSlice(t, []string{
+			"Constructing chat completion request",
+			"Sending chat completion request",
+			"Chat completion stream started",
+			"Received tool call",
+			"Chat completion stream ended",
+		}, messages)
+
+		is.Equal(t, any("get_weather"), logs[3]["tool_name"])
+		_, ok := logs[3]["tool_args"]
+		is.True(t, !ok, "should not log tool args without full content capture")
+		is.Equal(t, any("STOP"), logs[4]["finish_reason"])
+	})
+
+	t.Run("logs stream errors", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL: newChatServer(t, http.StatusTooManyRequests, `{"error":{"code":429,"message":"Resource exhausted.","status":"RESOURCE_EXHAUSTED"}}`),
+			Key:     "test",
+			Log:     slog.New(slog.NewJSONHandler(&buf, nil)),
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+		for range res.Parts() {
+		}
+
+		logs := decodeLogs(t, &buf)
+		is.Equal(t, 1, len(logs))
+		is.Equal(t, any("Chat completion stream failed"), logs[0]["msg"])
+		is.Equal(t, any("INFO"), logs[0]["level"])
+	})
+}
+
+func TestNewClient_RetryOptions(t *testing.T) {
+	t.Run("retries failed requests and logs the retries", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				http.Error(w, `{"error":{"code":503,"message":"Unavailable.","status":"UNAVAILABLE"}}`, http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(`data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hi!"}]},"finishReason":"STOP"}]}
+
+`))
+		}))
+		t.Cleanup(server.Close)
+
+		var buf bytes.Buffer
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL: server.URL,
+			Key:     "test",
+			Log:     slog.New(slog.NewJSONHandler(&buf, nil)),
+			RetryOptions: &genai.HTTPRetryOptions{
+				Attempts:     genai.Ptr[int32](3),
+				InitialDelay: genai.Ptr(0.0),
+				Jitter:       genai.Ptr(0.0),
+			},
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+		var output string
+		for part, err := range res.Parts() {
+			is.NotError(t, err)
+			output += part.Text()
+		}
+		is.Equal(t, "Hi!", output)
+		is.Equal(t, int32(2), requests.Load())
+
+		logs := decodeLogs(t, &buf)
+		is.Equal(t, 1, len(logs))
+		is.Equal(t, any("Retrying request"), logs[0]["msg"])
+		is.Equal(t, any("INFO"), logs[0]["level"])
+		is.Equal(t, any(float64(2)), logs[0]["attempt"])
+		is.Equal(t, any("503 Service Unavailable"), logs[0]["reason"])
+	})
+
+	t.Run("doesn't log requests that aren't retried", func(t *testing.T) {
+		// The upload is started, but the SDK doesn't retry the failing chunk request
+		var requests atomic.Int32
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			if r.URL.Path != "/upload/chunk" {
+				w.Header().Set("X-Goog-Upload-URL", server.URL+"/upload/chunk")
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+			w.Header().Set("X-Goog-Upload-Status", "final")
+			http.Error(w, `{"error":{"code":503,"message":"Unavailable.","status":"UNAVAILABLE"}}`, http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(server.Close)
+
+		var buf bytes.Buffer
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL: server.URL,
+			Key:     "test",
+			Log:     slog.New(slog.NewJSONHandler(&buf, nil)),
+			RetryOptions: &genai.HTTPRetryOptions{
+				Attempts:     genai.Ptr[int32](3),
+				InitialDelay: genai.Ptr(0.0),
+				Jitter:       genai.Ptr(0.0),
+			},
+		})
+		tr := c.NewTranscriber(google.NewTranscriberOptions{InlineLimit: 1})
+
+		for range 2 {
+			_, err := tr.Transcribe(t.Context(), google.TranscribeRequest{
+				Audio:    bytes.NewReader([]byte("audio")),
+				MIMEType: "audio/wav",
+			})
+			is.True(t, err != nil, "should error")
+		}
+
+		is.Equal(t, int32(4), requests.Load())
+		is.Equal(t, 0, len(decodeLogs(t, &buf)))
+	})
+}
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var logs []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var l map[string]any
+		is.NotError(t, dec.Decode(&l))
+		logs = append(logs, l)
+	}
+	return logs
+}
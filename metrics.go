@@ -0,0 +1,123 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/genai"
+)
+
+// metrics for chat completions, following the OpenTelemetry semantic conventions for generative AI clients
+// where they define a metric, and their naming otherwise.
+type metrics struct {
+	errors            metric.Int64Counter
+	operationDuration metric.Float64Histogram
+	requests          metric.Int64Counter
+	timeToFirstToken  metric.Float64Histogram
+	tokenUsage        metric.Int64Histogram
+	toolCalls         metric.Int64Counter
+}
+
+func newMetrics(mp metric.MeterProvider) *metrics {
+	meter := mp.Meter("maragu.dev/gai-google")
+
+	// Instrument creation only fails on invalid names or units, which are constant here
+	must := func(err error) {
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	var m metrics
+	var err error
+
+	m.errors, err = meter.Int64Counter("gen_ai.client.errors",
+		metric.WithDescription("Number of failed GenAI operations, by error type."),
+		metric.WithUnit("{error}"))
+	must(err)
+
+	m.operationDuration, err = meter.Float64Histogram("gen_ai.client.operation.duration",
+		metric.WithDescription("GenAI operation duration, until the response stream ends."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.02, 0.04, 0.08, 0.16, 0.32, 0.64, 1.28, 2.56, 5.12, 10.24, 20.48, 40.96, 81.92))
+	must(err)
+
+	m.requests, err = meter.Int64Counter("gen_ai.client.requests",
+		metric.WithDescription("Number of GenAI operations started."),
+		metric.WithUnit("{request}"))
+	must(err)
+
+	m.timeToFirstToken, err = meter.Float64Histogram("gen_ai.client.time_to_first_token",
+		metric.WithDescription("Time from the start of the GenAI operation to the first content in the response."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.02, 0.04, 0.06, 0.08, 0.1, 0.25, 0.5, 0.75, 1.0, 2.5, 5.0, 7.5, 10.0))
+	must(err)
+
+	m.tokenUsage, err = meter.Int64Histogram("gen_ai.client.token.usage",
+		metric.WithDescription("Number of input and output tokens used."),
+		metric.WithUnit("{token}"),
+		metric.WithExplicitBucketBoundaries(1, 4, 16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304, 16777216, 67108864))
+	must(err)
+
+	m.toolCalls, err = meter.Int64Counter("gen_ai.client.tool_calls",
+		metric.WithDescription("Number of tool calls in GenAI responses, by tool name."),
+		metric.WithUnit("{call}"))
+	must(err)
+
+	return &m
+}
+
+func (m *metrics) recordRequest(ctx context.Context, attrs []attribute.KeyValue) {
+	m.requests.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+func (m *metrics) recordFirstToken(ctx context.Context, attrs []attribute.KeyValue, start time.Time) {
+	m.timeToFirstToken.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+}
+
+func (m *metrics) recordToolCall(ctx context.Context, attrs []attribute.KeyValue, name string) {
+	m.toolCalls.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("gen_ai.tool.name", name))...))
+}
+
+// recordEnd of the operation, with the token usage if there's no error.
+func (m *metrics) recordEnd(ctx context.Context, attrs []attribute.KeyValue, start time.Time, usage *UsageDetails, err error) {
+	if err != nil {
+		attrs = append(attrs, attribute.String("error.type", errorType(err)))
+		m.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+
+	m.operationDuration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+
+	if err != nil || usage == nil {
+		return
+	}
+
+	m.tokenUsage.Record(ctx, int64(usage.PromptTokens.Total+usage.ToolUsePromptTokens.Total),
+		metric.WithAttributes(append(attrs, attribute.String("gen_ai.token.type", "input"))...))
+	m.tokenUsage.Record(ctx, int64(usage.CompletionTokens.Total+usage.ThoughtsTokens),
+		metric.WithAttributes(append(attrs, attribute.String("gen_ai.token.type", "output"))...))
+}
+
+// errorType for the error.type attribute: the HTTP status code for API errors, or a short description.
+func errorType(err error) string {
+	var apiErr genai.APIError
+	var apiErrPtr *genai.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return strconv.Itoa(apiErr.Code)
+	case errors.As(err, &apiErrPtr):
+		return strconv.Itoa(apiErrPtr.Code)
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, ErrUnsupportedByModel):
+		return "unsupported_by_model"
+	default:
+		return "_OTHER"
+	}
+}
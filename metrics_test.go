@@ -0,0 +1,122 @@
+package google_test
+
+import (
+	"net/http"
+	"testing"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestChatCompleter_ChatComplete_Metrics(t *testing.T) {
+	t.Run("records requests, durations, token usage, and tool calls", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		cc := newMetricsChatCompleter(t, reader, http.StatusOK, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Let me check."}]}}],"usageMetadata":{"promptTokenCount":10,"totalTokenCount":10}}
+
+data: {"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Copenhagen"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"thoughtsTokenCount":3,"totalTokenCount":18}}
+
+`)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("What's the weather in Copenhagen?")},
+		})
+		is.NotError(t, err)
+
+		for _, err := range res.Parts() {
+			is.NotError(t, err)
+		}
+
+		metrics := collectMetrics(t, reader)
+
+		requests := metrics["gen_ai.client.requests"].Data.(metricdata.Sum[int64])
+		is.Equal(t, 1, len(requests.DataPoints))
+		is.Equal(t, int64(1), requests.DataPoints[0].Value)
+		model, _ := requests.DataPoints[0].Attributes.Value("gen_ai.request.model")
+		is.Equal(t, "gemini-2.5-flash", model.AsString())
+		system, _ := requests.DataPoints[0].Attributes.Value("gen_ai.system")
+		is.Equal(t, "gcp.gemini", system.AsString())
+
+		duration := metrics["gen_ai.client.operation.duration"].Data.(metricdata.Histogram[float64])
+		is.Equal(t, uint64(1), duration.DataPoints[0].Count)
+
+		ttft := metrics["gen_ai.client.time_to_first_token"].Data.(metricdata.Histogram[float64])
+		is.Equal(t, uint64(1), ttft.DataPoints[0].Count)
+
+		tokens := metrics["gen_ai.client.token.usage"].Data.(metricdata.Histogram[int64])
+		is.Equal(t, 2, len(tokens.DataPoints))
+		for _, dp := range tokens.DataPoints {
+			tokenType, _ := dp.Attributes.Value("gen_ai.token.type")
+			switch tokenType.AsString() {
+			case "input":
+				is.Equal(t, int64(10), dp.Sum)
+			case "output":
+				is.Equal(t, int64(8), dp.Sum)
+			default:
+				t.Fatal("unexpected token type", tokenType.AsString())
+			}
+		}
+
+		toolCalls := metrics["gen_ai.client.tool_calls"].Data.(metricdata.Sum[int64])
+		is.Equal(t, int64(1), toolCalls.DataPoints[0].Value)
+		name, _ := toolCalls.DataPoints[0].Attributes.Value("gen_ai.tool.name")
+		is.Equal(t, "get_weather", name.AsString())
+
+		_, ok := metrics["gen_ai.client.errors"]
+		is.True(t, !ok, "should not have errors")
+	})
+
+	t.Run("records errors by type", func(t *testing.T) {
+		reader := sdkmetric.NewManualReader()
+		cc := newMetricsChatCompleter(t, reader, http.StatusTooManyRequests,
+			`{"error":{"code":429,"message":"Resource exhausted.","status":"RESOURCE_EXHAUSTED"}}`)
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+
+		var streamErr error
+		for _, err := range res.Parts() {
+			streamErr = err
+		}
+		is.True(t, streamErr != nil, "should have stream error")
+
+		metrics := collectMetrics(t, reader)
+
+		errs := metrics["gen_ai.client.errors"].Data.(metricdata.Sum[int64])
+		is.Equal(t, int64(1), errs.DataPoints[0].Value)
+		errorType, _ := errs.DataPoints[0].Attributes.Value("error.type")
+		is.Equal(t, "429", errorType.AsString())
+
+		_, ok := metrics["gen_ai.client.token.usage"]
+		is.True(t, !ok, "should not have token usage")
+	})
+}
+
+// newMetricsChatCompleter with a local stand-in for the streaming endpoint, recording metrics with the reader.
+func newMetricsChatCompleter(t *testing.T, reader sdkmetric.Reader, status int, body string) *google.ChatCompleter {
+	t.Helper()
+
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	c := google.NewClient(google.NewClientOptions{BaseURL: newChatServer(t, status, body), Key: "test", MeterProvider: mp})
+	return c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+}
+
+func collectMetrics(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Metrics {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	is.NotError(t, reader.Collect(t.Context(), &rm))
+
+	metrics := map[string]metricdata.Metrics{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m
+		}
+	}
+	return metrics
+}
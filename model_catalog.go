@@ -0,0 +1,228 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+)
+
+// ErrUnsupportedByModel is returned if a request uses a feature the model doesn't support,
+// according to the [ModelCatalog] or the table of known models, see [LookupModel].
+var ErrUnsupportedByModel = errors.New("unsupported by model")
+
+// Generation methods in [ModelInfo.GenerationMethods].
+const (
+	GenerationMethodBidiGenerateContent = "bidiGenerateContent"
+	GenerationMethodEmbedContent        = "embedContent"
+	GenerationMethodGenerateContent     = "generateContent"
+	GenerationMethodPredict             = "predict"
+	GenerationMethodPredictLongRunning  = "predictLongRunning"
+)
+
+// ModelInfo about a model, from the API.
+type ModelInfo struct {
+	// Name of the model, like "models/gemini-2.5-flash".
+	Name string
+
+	Description string
+	DisplayName string
+	Version     string
+
+	// InputTokenLimit and OutputTokenLimit are the maximum number of tokens in a request and response.
+	InputTokenLimit  int
+	OutputTokenLimit int
+
+	// GenerationMethods the model supports, like [GenerationMethodGenerateContent].
+	// Vertex AI doesn't return these for Google models, in which case it's empty.
+	GenerationMethods []string
+
+	// Capabilities from the table of known models, see [LookupModel].
+	// The API doesn't return most capabilities, so they're inferred for other models.
+	// Models without chat completion, like embedding, Imagen, and Veo models, have no capabilities except Thinking.
+	Capabilities ModelCapabilities
+}
+
+// Supports returns whether the model supports the generation method.
+// If the generation methods are unknown, it returns true.
+func (m ModelInfo) Supports(method string) bool {
+	return len(m.GenerationMethods) == 0 || slices.Contains(m.GenerationMethods, method)
+}
+
+// ListModels available to the client.
+func (c *Client) ListModels(ctx context.Context) ([]ModelInfo, error) {
+	ctx, span := c.tracer.Start(ctx, "google.list_models",
+		trace.WithSpanKind(trace.SpanKindClient),
+	)
+	defer span.End()
+
+	var models []ModelInfo
+	for model, err := range c.Client.Models.All(trackRetries(ctx)) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "model listing failed")
+			return nil, fmt.Errorf("error listing models: %w", err)
+		}
+		models = append(models, convertModel(model))
+	}
+
+	span.SetAttributes(attribute.Int("ai.model_count", len(models)))
+
+	return models, nil
+}
+
+// GetModel info by name, like "models/gemini-2.5-flash".
+func (c *Client) GetModel(ctx context.Context, name string) (ModelInfo, error) {
+	ctx, span := c.tracer.Start(ctx, "google.get_model",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", name),
+		),
+	)
+	defer span.End()
+
+	model, err := c.Client.Models.Get(trackRetries(ctx), modelName(c.Client, name), nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "model get failed")
+		return ModelInfo{}, fmt.Errorf("error getting model: %w", err)
+	}
+
+	return convertModel(model), nil
+}
+
+func convertModel(model *genai.Model) ModelInfo {
+	name := model.Name
+	if !strings.Contains(name, "/") {
+		name = "models/" + name
+	}
+
+	info := ModelInfo{
+		Name:              name,
+		Description:       model.Description,
+		DisplayName:       model.DisplayName,
+		Version:           model.Version,
+		InputTokenLimit:   int(model.InputTokenLimit),
+		OutputTokenLimit:  int(model.OutputTokenLimit),
+		GenerationMethods: model.SupportedActions,
+	}
+
+	if spec, ok := LookupModel(name); ok {
+		info.Capabilities = spec.Capabilities
+	} else {
+		info.Capabilities = inferCapabilities(name, model.SupportedActions)
+	}
+	info.Capabilities.Thinking = info.Capabilities.Thinking || model.Thinking
+
+	return info
+}
+
+// ModelCatalog is an in-memory cache of [ModelInfo], safe for concurrent use.
+// Use it with [NewChatCompleterOptions.ModelCatalog] to validate requests before sending them.
+type ModelCatalog struct {
+	client *Client
+	lock   sync.RWMutex
+	log    *slog.Logger
+	models map[string]cachedModelInfo
+	ttl    time.Duration
+}
+
+type cachedModelInfo struct {
+	info    ModelInfo
+	expires time.Time
+}
+
+type NewModelCatalogOptions struct {
+	// TTL is how long model info is cached. Defaults to 1 hour.
+	TTL time.Duration
+}
+
+func (c *Client) NewModelCatalog(opts NewModelCatalogOptions) *ModelCatalog {
+	if opts.TTL <= 0 {
+		opts.TTL = time.Hour
+	}
+
+	return &ModelCatalog{
+		client: c,
+		log:    c.log,
+		models: map[string]cachedModelInfo{},
+		ttl:    opts.TTL,
+	}
+}
+
+// Get model info by name, from the cache if present and not expired.
+func (m *ModelCatalog) Get(ctx context.Context, name string) (ModelInfo, error) {
+	m.lock.RLock()
+	cached, ok := m.models[name]
+	m.lock.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.info, nil
+	}
+
+	log := logWithTrace(ctx, m.log).With("model", name)
+	log.Debug("Model not cached, fetching model info")
+
+	info, err := m.client.GetModel(ctx, name)
+	if err != nil {
+		log.Info("Error fetching model info", "error", err)
+		return ModelInfo{}, err
+	}
+
+	m.lock.Lock()
+	m.models[name] = cachedModelInfo{info: info, expires: time.Now().Add(m.ttl)}
+	m.lock.Unlock()
+
+	return info, nil
+}
+
+// List all models, refreshing the cache.
+func (m *ModelCatalog) List(ctx context.Context) ([]ModelInfo, error) {
+	log := logWithTrace(ctx, m.log)
+	log.Debug("Listing models")
+
+	models, err := m.client.ListModels(ctx)
+	if err != nil {
+		log.Info("Error listing models", "error", err)
+		return nil, err
+	}
+
+	expires := time.Now().Add(m.ttl)
+	m.lock.Lock()
+	for _, info := range models {
+		m.models[info.Name] = cachedModelInfo{info: info, expires: expires}
+	}
+	m.lock.Unlock()
+	log.Debug("Cached model infos", "model_count", len(models))
+
+	return models, nil
+}
+
+// validateChatComplete checks that the model supports the chat completion request.
+// If the model info can't be fetched, it falls back to the known models, see [LookupModel],
+// so a catalog outage doesn't fail requests. Unknown models are then not validated.
+func (m *ModelCatalog) validateChatComplete(ctx context.Context, model ChatCompleteModel, req ChatCompleteRequest, responseModalities []genai.Modality, codeExecution bool) error {
+	info, err := m.Get(ctx, string(model))
+	if err != nil {
+		logWithTrace(ctx, m.log).Info("Model catalog lookup failed, falling back to known models", "model", model, "error", err)
+		spec, ok := LookupModel(model)
+		if !ok {
+			return nil
+		}
+		return validateChatComplete(model, spec.Capabilities, spec.OutputTokenLimit, req, responseModalities, codeExecution)
+	}
+
+	if !info.Supports(GenerationMethodGenerateContent) {
+		return fmt.Errorf("%w: %v doesn't support chat completion", ErrUnsupportedByModel, model)
+	}
+
+	return validateChatComplete(model, info.Capabilities, info.OutputTokenLimit, req, responseModalities, codeExecution)
+}
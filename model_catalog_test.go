@@ -0,0 +1,173 @@
+package google_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestClient_ListModels(t *testing.T) {
+	t.Run("can list models", func(t *testing.T) {
+		c := newClient(t)
+
+		models, err := c.ListModels(t.Context())
+		is.NotError(t, err)
+
+		i := slices.IndexFunc(models, func(m google.ModelInfo) bool {
+			return m.Name == string(google.ChatCompleteModelGemini2_5Flash)
+		})
+		is.True(t, i >= 0, "should have Gemini 2.5 Flash")
+		is.True(t, models[i].Supports(google.GenerationMethodGenerateContent), "should support generateContent")
+		is.True(t, models[i].InputTokenLimit > 0, "should have input token limit")
+	})
+
+	t.Run("infers capabilities of unknown models from the generation methods", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"models": [
+				{"name": "models/gemini-unknown", "supportedGenerationMethods": ["generateContent", "countTokens"]},
+				{"name": "models/text-embedding-004", "supportedGenerationMethods": ["embedContent"]},
+				{"name": "models/veo-2.0-generate-001", "supportedGenerationMethods": ["predictLongRunning"]}
+			]}`))
+		}))
+		t.Cleanup(server.Close)
+		c := google.NewClient(google.NewClientOptions{BaseURL: server.URL, Key: "test"})
+
+		models, err := c.ListModels(t.Context())
+		is.NotError(t, err)
+
+		is.Equal(t, 3, len(models))
+		is.True(t, models[0].Capabilities.Tools, "should have tools")
+		is.True(t, models[0].Capabilities.StructuredOutput, "should have structured output")
+		for _, model := range models[1:] {
+			is.Equal(t, google.ModelCapabilities{}, model.Capabilities)
+		}
+	})
+}
+
+func TestClient_GetModel(t *testing.T) {
+	t.Run("can get a model", func(t *testing.T) {
+		c := newClient(t)
+
+		model, err := c.GetModel(t.Context(), string(google.ChatCompleteModelGemini2_5FlashImage))
+		is.NotError(t, err)
+
+		is.Equal(t, string(google.ChatCompleteModelGemini2_5FlashImage), model.Name)
+		is.True(t, model.OutputTokenLimit > 0, "should have output token limit")
+		is.True(t, model.Capabilities.ImageOutput, "should have image output")
+	})
+}
+
+func TestModelCatalog_Get(t *testing.T) {
+	t.Run("caches model info", func(t *testing.T) {
+		c, requests := newModelServer(t)
+		mc := c.NewModelCatalog(google.NewModelCatalogOptions{})
+
+		model, err := mc.Get(t.Context(), "models/gemini-2.5-flash")
+		is.NotError(t, err)
+		is.Equal(t, "models/gemini-2.5-flash", model.Name)
+		is.Equal(t, "Gemini 2.5 Flash", model.DisplayName)
+		is.Equal(t, 1048576, model.InputTokenLimit)
+		is.Equal(t, 65536, model.OutputTokenLimit)
+		is.EqualSlice(t, []string{"generateContent", "countTokens"}, model.GenerationMethods)
+		is.True(t, model.Capabilities.Thinking, "should have thinking")
+		is.True(t, !model.Capabilities.ImageOutput, "should not have image output")
+
+		_, err = mc.Get(t.Context(), "models/gemini-2.5-flash")
+		is.NotError(t, err)
+		is.Equal(t, int64(1), requests.Load())
+	})
+}
+
+func TestChatCompleter_ChatComplete_ModelCatalog(t *testing.T) {
+	t.Run("rejects image output on a model without it before sending", func(t *testing.T) {
+		c, requests := newModelServer(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:              google.ChatCompleteModelGemini2_5Flash,
+			ModelCatalog:       c.NewModelCatalog(google.NewModelCatalogOptions{}),
+			ResponseModalities: []genai.Modality{genai.ModalityText, genai.ModalityImage},
+		})
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Draw a cat.")},
+		})
+		is.True(t, errors.Is(err, google.ErrUnsupportedByModel), "should be unsupported by model")
+		is.Equal(t, int64(1), requests.Load())
+	})
+
+	t.Run("rejects max completion tokens above the output token limit", func(t *testing.T) {
+		c, _ := newModelServer(t)
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:        google.ChatCompleteModelGemini2_5Flash,
+			ModelCatalog: c.NewModelCatalog(google.NewModelCatalogOptions{}),
+		})
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			MaxCompletionTokens: gai.Ptr(100000),
+			Messages:            []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.True(t, errors.Is(err, google.ErrUnsupportedByModel), "should be unsupported by model")
+	})
+
+	t.Run("falls back to the known models if the catalog lookup fails", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{BaseURL: newChatServer(t, http.StatusOK, toolCallStream), Key: "test"})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:              google.ChatCompleteModelGemini2_5Flash,
+			ModelCatalog:       c.NewModelCatalog(google.NewModelCatalogOptions{}),
+			ResponseModalities: []genai.Modality{genai.ModalityText, genai.ModalityImage},
+		})
+
+		_, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Draw a cat.")},
+		})
+		is.True(t, errors.Is(err, google.ErrUnsupportedByModel), "should be unsupported by model")
+	})
+
+	t.Run("sends the request for an unknown model if the catalog lookup fails", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{BaseURL: newChatServer(t, http.StatusOK, toolCallStream), Key: "test"})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+			Model:        google.ChatCompleteModel("models/gemini-unknown"),
+			ModelCatalog: c.NewModelCatalog(google.NewModelCatalogOptions{}),
+		})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+	})
+}
+
+// newModelServer is a local stand-in for the model get endpoint, counting the requests.
+func newModelServer(t *testing.T) (*google.Client, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !strings.HasSuffix(r.URL.Path, "/models/gemini-2.5-flash") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"name": "models/gemini-2.5-flash",
+			"displayName": "Gemini 2.5 Flash",
+			"inputTokenLimit": 1048576,
+			"outputTokenLimit": 65536,
+			"supportedGenerationMethods": ["generateContent", "countTokens"],
+			"thinking": true
+		}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return google.NewClient(google.NewClientOptions{BaseURL: server.URL, Key: "test"}), &requests
+}
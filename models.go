@@ -0,0 +1,340 @@
+package google
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+type EmbedModel string
+
+const (
+	EmbedModelGeminiEmbedding001 = EmbedModel("models/gemini-embedding-001")
+)
+
+// ModelFamily groups models by what they're used for, and which type of this package they're used with.
+type ModelFamily string
+
+const (
+	// ModelFamilyChat models are used with [ChatCompleter] and [Transcriber].
+	ModelFamilyChat = ModelFamily("chat")
+
+	ModelFamilyEmbedding = ModelFamily("embedding")
+
+	// ModelFamilyImage models are used with [ImageGenerator].
+	ModelFamilyImage = ModelFamily("image")
+
+	// ModelFamilyImageEdit models are used with [ImageEditor.EditImage].
+	ModelFamilyImageEdit = ModelFamily("image_edit")
+
+	// ModelFamilyImageUpscale models are used with [ImageEditor.UpscaleImage].
+	ModelFamilyImageUpscale = ModelFamily("image_upscale")
+
+	// ModelFamilyLive models are used with [LiveSession].
+	ModelFamilyLive = ModelFamily("live")
+
+	// ModelFamilySpeech models are used with [SpeechSynthesizer].
+	ModelFamilySpeech = ModelFamily("speech")
+
+	// ModelFamilyVideo models are used with [VideoGenerator].
+	ModelFamilyVideo = ModelFamily("video")
+)
+
+// ModelCapabilities of a model.
+type ModelCapabilities struct {
+	AudioInput       bool
+	AudioOutput      bool
+	CodeExecution    bool
+	ImageInput       bool
+	ImageOutput      bool
+	StructuredOutput bool
+	Thinking         bool
+	Tools            bool
+	VideoInput       bool
+	VideoOutput      bool
+}
+
+// ModelSpec is the known specification of a model, see [LookupModel].
+type ModelSpec struct {
+	// Name of the model, like "models/gemini-2.5-flash".
+	Name string
+
+	Family ModelFamily
+
+	// Default is set for the model used if no model is given for the family.
+	Default bool
+
+	// InputTokenLimit and OutputTokenLimit are the maximum number of tokens in a request and response.
+	// Zero means not applicable.
+	InputTokenLimit  int
+	OutputTokenLimit int
+
+	Capabilities ModelCapabilities
+}
+
+// chatCapabilities are shared by most Gemini chat models.
+var chatCapabilities = ModelCapabilities{
+	AudioInput:       true,
+	CodeExecution:    true,
+	ImageInput:       true,
+	StructuredOutput: true,
+	Tools:            true,
+	VideoInput:       true,
+}
+
+// modelSpecs is the table of known models, in order of family.
+var modelSpecs = []ModelSpec{
+	{
+		Name:             string(ChatCompleteModelGemini2_0Flash),
+		Family:           ModelFamilyChat,
+		InputTokenLimit:  1_048_576,
+		OutputTokenLimit: 8_192,
+		Capabilities:     chatCapabilities,
+	},
+	{
+		Name:             string(ChatCompleteModelGemini2_0FlashLite),
+		Family:           ModelFamilyChat,
+		InputTokenLimit:  1_048_576,
+		OutputTokenLimit: 8_192,
+		Capabilities:     with(chatCapabilities, func(c *ModelCapabilities) { c.CodeExecution = false }),
+	},
+	{
+		Name:             string(ChatCompleteModelGemini2_5Flash),
+		Family:           ModelFamilyChat,
+		Default:          true,
+		InputTokenLimit:  1_048_576,
+		OutputTokenLimit: 65_536,
+		Capabilities:     with(chatCapabilities, func(c *ModelCapabilities) { c.Thinking = true }),
+	},
+	{
+		Name:             string(ChatCompleteModelGemini2_5FlashImage),
+		Family:           ModelFamilyChat,
+		InputTokenLimit:  32_768,
+		OutputTokenLimit: 32_768,
+		Capabilities:     ModelCapabilities{ImageInput: true, ImageOutput: true, StructuredOutput: true},
+	},
+	{
+		Name:             string(ChatCompleteModelGemini2_5FlashImagePreview),
+		Family:           ModelFamilyChat,
+		InputTokenLimit:  32_768,
+		OutputTokenLimit: 32_768,
+		Capabilities:     ModelCapabilities{ImageInput: true, ImageOutput: true, StructuredOutput: true},
+	},
+	{
+		Name:             string(ChatCompleteModelGemini2_5FlashLite),
+		Family:           ModelFamilyChat,
+		InputTokenLimit:  1_048_576,
+		OutputTokenLimit: 65_536,
+		Capabilities:     with(chatCapabilities, func(c *ModelCapabilities) { c.Thinking = true }),
+	},
+	{
+		Name:             string(ChatCompleteModelGemini2_5Pro),
+		Family:           ModelFamilyChat,
+		InputTokenLimit:  1_048_576,
+		OutputTokenLimit: 65_536,
+		Capabilities:     with(chatCapabilities, func(c *ModelCapabilities) { c.Thinking = true }),
+	},
+
+	{
+		Name:            string(EmbedModelGeminiEmbedding001),
+		Family:          ModelFamilyEmbedding,
+		Default:         true,
+		InputTokenLimit: 2_048,
+	},
+
+	{
+		Name:            string(ImageGenerateModelImagen4),
+		Family:          ModelFamilyImage,
+		Default:         true,
+		InputTokenLimit: 480,
+		Capabilities:    ModelCapabilities{ImageOutput: true},
+	},
+	{
+		Name:            string(ImageGenerateModelImagen4Fast),
+		Family:          ModelFamilyImage,
+		InputTokenLimit: 480,
+		Capabilities:    ModelCapabilities{ImageOutput: true},
+	},
+	{
+		Name:            string(ImageGenerateModelImagen4Ultra),
+		Family:          ModelFamilyImage,
+		InputTokenLimit: 480,
+		Capabilities:    ModelCapabilities{ImageOutput: true},
+	},
+
+	{
+		Name:         string(ImageEditModelImagen3Capability),
+		Family:       ModelFamilyImageEdit,
+		Default:      true,
+		Capabilities: ModelCapabilities{ImageInput: true, ImageOutput: true},
+	},
+
+	{
+		Name:         string(ImageEditModelImagen4Upscale),
+		Family:       ModelFamilyImageUpscale,
+		Default:      true,
+		Capabilities: ModelCapabilities{ImageInput: true, ImageOutput: true},
+	},
+
+	{
+		Name:             string(LiveModelGemini2_0FlashLive),
+		Family:           ModelFamilyLive,
+		InputTokenLimit:  1_048_576,
+		OutputTokenLimit: 8_192,
+		Capabilities:     ModelCapabilities{AudioInput: true, AudioOutput: true, ImageInput: true, Tools: true, VideoInput: true},
+	},
+	{
+		Name:             string(LiveModelGemini2_5FlashNativeAudio),
+		Family:           ModelFamilyLive,
+		Default:          true,
+		InputTokenLimit:  131_072,
+		OutputTokenLimit: 8_192,
+		Capabilities:     ModelCapabilities{AudioInput: true, AudioOutput: true, ImageInput: true, Thinking: true, Tools: true, VideoInput: true},
+	},
+
+	{
+		Name:             string(SpeechModelGemini2_5FlashTTS),
+		Family:           ModelFamilySpeech,
+		Default:          true,
+		InputTokenLimit:  8_192,
+		OutputTokenLimit: 16_384,
+		Capabilities:     ModelCapabilities{AudioOutput: true},
+	},
+	{
+		Name:             string(SpeechModelGemini2_5ProTTS),
+		Family:           ModelFamilySpeech,
+		InputTokenLimit:  8_192,
+		OutputTokenLimit: 16_384,
+		Capabilities:     ModelCapabilities{AudioOutput: true},
+	},
+
+	{
+		Name:            string(VideoGenerateModelVeo3),
+		Family:          ModelFamilyVideo,
+		Default:         true,
+		InputTokenLimit: 1_024,
+		Capabilities:    ModelCapabilities{AudioOutput: true, ImageInput: true, VideoOutput: true},
+	},
+	{
+		Name:            string(VideoGenerateModelVeo3Fast),
+		Family:          ModelFamilyVideo,
+		InputTokenLimit: 1_024,
+		Capabilities:    ModelCapabilities{AudioOutput: true, ImageInput: true, VideoOutput: true},
+	},
+}
+
+func with(c ModelCapabilities, f func(c *ModelCapabilities)) ModelCapabilities {
+	f(&c)
+	return c
+}
+
+// LookupModel in the table of known models, by name with or without the "models/" prefix.
+func LookupModel[T ~string](name T) (ModelSpec, bool) {
+	n := string(name)
+	if i := strings.LastIndex(n, "/"); i >= 0 {
+		n = n[i+1:]
+	}
+	n = "models/" + n
+
+	for _, spec := range modelSpecs {
+		if spec.Name == n {
+			return spec, true
+		}
+	}
+	return ModelSpec{}, false
+}
+
+// ModelSpecs returns the table of known models.
+func ModelSpecs() []ModelSpec {
+	return append([]ModelSpec{}, modelSpecs...)
+}
+
+// defaultModel of the family in the table of known models.
+func defaultModel[T ~string](family ModelFamily) T {
+	for _, spec := range modelSpecs {
+		if spec.Family == family && spec.Default {
+			return T(spec.Name)
+		}
+	}
+	panic("no default model for family " + family)
+}
+
+// inferCapabilities of a model that's not in the table of known models, from its name and generation methods.
+// Models without chat completion, like embedding, Imagen, and Veo models, have no capabilities.
+// Most chat models take all input modalities and support tools, so only the output modalities are inferred from the name.
+func inferCapabilities(name string, generationMethods []string) ModelCapabilities {
+	if !isChatModel(name, generationMethods) {
+		return ModelCapabilities{}
+	}
+
+	c := chatCapabilities
+	switch {
+	case strings.Contains(name, "-image"):
+		c.ImageOutput = true
+	case strings.Contains(name, "-tts"), strings.Contains(name, "native-audio"):
+		c.AudioOutput = true
+	}
+	return c
+}
+
+// isChatModel returns whether the model supports chat completion, live or not.
+// Vertex AI doesn't return generation methods for Google models, so then it's inferred from the name.
+func isChatModel(name string, generationMethods []string) bool {
+	if len(generationMethods) == 0 {
+		return strings.Contains(name, "gemini") && !strings.Contains(name, "embedding")
+	}
+	return slices.Contains(generationMethods, GenerationMethodGenerateContent) ||
+		slices.Contains(generationMethods, GenerationMethodBidiGenerateContent)
+}
+
+// validateChatComplete checks that a model with the capabilities and output token limit supports the request.
+func validateChatComplete(model ChatCompleteModel, capabilities ModelCapabilities, outputTokenLimit int, req ChatCompleteRequest, responseModalities []genai.Modality, codeExecution bool) error {
+	for _, modality := range responseModalities {
+		switch modality {
+		case genai.ModalityImage:
+			if !capabilities.ImageOutput {
+				return fmt.Errorf("%w: %v doesn't support image output", ErrUnsupportedByModel, model)
+			}
+		case genai.ModalityAudio:
+			if !capabilities.AudioOutput {
+				return fmt.Errorf("%w: %v doesn't support audio output", ErrUnsupportedByModel, model)
+			}
+		}
+	}
+
+	if req.MaxCompletionTokens != nil && outputTokenLimit > 0 && *req.MaxCompletionTokens > outputTokenLimit {
+		return fmt.Errorf("%w: max completion tokens %v is above the output token limit %v of %v",
+			ErrUnsupportedByModel, *req.MaxCompletionTokens, outputTokenLimit, model)
+	}
+
+	if len(req.Tools) > 0 && !capabilities.Tools {
+		return fmt.Errorf("%w: %v doesn't support tools", ErrUnsupportedByModel, model)
+	}
+
+	if req.ResponseSchema != nil && !capabilities.StructuredOutput {
+		return fmt.Errorf("%w: %v doesn't support structured output", ErrUnsupportedByModel, model)
+	}
+
+	if codeExecution && !capabilities.CodeExecution {
+		return fmt.Errorf("%w: %v doesn't support code execution", ErrUnsupportedByModel, model)
+	}
+
+	for _, m := range req.Messages {
+		for _, part := range m.Parts {
+			if part.Type != gai.MessagePartTypeData {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(part.MIMEType, "image/") && !capabilities.ImageInput,
+				strings.HasPrefix(part.MIMEType, "audio/") && !capabilities.AudioInput,
+				strings.HasPrefix(part.MIMEType, "video/") && !capabilities.VideoInput:
+				return fmt.Errorf("%w: %v doesn't support %v input", ErrUnsupportedByModel, model, part.MIMEType)
+			}
+		}
+	}
+
+	return nil
+}
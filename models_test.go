@@ -0,0 +1,110 @@
+package google_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestLookupModel(t *testing.T) {
+	t.Run("can look up a model by name with or without prefix", func(t *testing.T) {
+		spec, ok := google.LookupModel(google.ChatCompleteModelGemini2_5FlashLite)
+		is.True(t, ok, "should find model")
+		is.Equal(t, string(google.ChatCompleteModelGemini2_5FlashLite), spec.Name)
+		is.Equal(t, google.ModelFamilyChat, spec.Family)
+		is.Equal(t, 65536, spec.OutputTokenLimit)
+		is.True(t, spec.Capabilities.Thinking, "should have thinking")
+
+		spec, ok = google.LookupModel("gemini-2.5-flash-preview-tts")
+		is.True(t, ok, "should find model")
+		is.Equal(t, google.ModelFamilySpeech, spec.Family)
+		is.True(t, spec.Capabilities.AudioOutput, "should have audio output")
+	})
+
+	t.Run("does not find unknown models", func(t *testing.T) {
+		_, ok := google.LookupModel("models/unknown")
+		is.True(t, !ok, "should not find model")
+	})
+}
+
+func TestModelSpecs(t *testing.T) {
+	t.Run("has exactly one default model per family", func(t *testing.T) {
+		defaults := map[google.ModelFamily]int{}
+		for _, spec := range google.ModelSpecs() {
+			if spec.Default {
+				defaults[spec.Family]++
+			}
+		}
+
+		for _, family := range []google.ModelFamily{
+			google.ModelFamilyChat,
+			google.ModelFamilyEmbedding,
+			google.ModelFamilyImage,
+			google.ModelFamilyImageEdit,
+			google.ModelFamilyImageUpscale,
+			google.ModelFamilyLive,
+			google.ModelFamilySpeech,
+			google.ModelFamilyVideo,
+		} {
+			is.Equal(t, 1, defaults[family], family)
+		}
+	})
+}
+
+func TestChatCompleter_ChatComplete_ModelSpec(t *testing.T) {
+	tests := []struct {
+		name               string
+		model              google.ChatCompleteModel
+		responseModalities []genai.Modality
+		req                gai.ChatCompleteRequest
+	}{
+		{
+			name:               "rejects image output on a text-only model",
+			model:              google.ChatCompleteModelGemini2_0Flash,
+			responseModalities: []genai.Modality{genai.ModalityText, genai.ModalityImage},
+			req:                gai.ChatCompleteRequest{Messages: []gai.Message{gai.NewUserTextMessage("Draw a cat.")}},
+		},
+		{
+			name:  "rejects tools on a model without tool support",
+			model: google.ChatCompleteModelGemini2_5FlashImage,
+			req: gai.ChatCompleteRequest{
+				Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+				Tools:    []gai.Tool{newWaitForTool(nil, nil)},
+			},
+		},
+		{
+			name:  "rejects audio input on a model without audio input",
+			model: google.ChatCompleteModelGemini2_5FlashImage,
+			req: gai.ChatCompleteRequest{
+				Messages: []gai.Message{gai.NewUserDataMessage("audio/mp4", bytes.NewReader(audio))},
+			},
+		},
+		{
+			name:  "rejects max completion tokens above the output token limit",
+			model: google.ChatCompleteModelGemini2_0Flash,
+			req: gai.ChatCompleteRequest{
+				MaxCompletionTokens: gai.Ptr(10000),
+				Messages:            []gai.Message{gai.NewUserTextMessage("Hi!")},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+			cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+				Model:              test.model,
+				ResponseModalities: test.responseModalities,
+			})
+
+			_, err := cc.ChatComplete(t.Context(), test.req)
+			is.True(t, errors.Is(err, google.ErrUnsupportedByModel), "should be unsupported by model")
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package google
+
+import (
+	"cmp"
+)
+
+// ModelPricing of a model in US dollars per million tokens.
+// Zero prices for audio, image, and thinking tokens fall back to the corresponding text price.
+type ModelPricing struct {
+	// Input is the price of text, image, and video input tokens, including tool use prompt tokens.
+	Input      float64
+	AudioInput float64
+
+	// CachedInput is the price of input tokens read from the context cache.
+	CachedInput      float64
+	CachedAudioInput float64
+
+	// Output is the price of text output tokens.
+	Output      float64
+	ImageOutput float64
+
+	// Thinking is the price of thinking tokens. Falls back to Output.
+	Thinking float64
+
+	// LongContextThreshold is the number of prompt tokens above which LongContext prices apply instead.
+	// Zero means there are no long context prices.
+	LongContextThreshold int
+	LongContext          *ModelPricing
+}
+
+// CostEstimate in US dollars, based on [ModelPricing] and token usage.
+type CostEstimate struct {
+	Input  float64
+	Output float64
+	Total  float64
+}
+
+// pricing is the table of default prices for the paid tier of the Gemini API.
+var pricing = map[ChatCompleteModel]ModelPricing{
+	ChatCompleteModelGemini2_0Flash: {
+		Input:            0.10,
+		AudioInput:       0.70,
+		CachedInput:      0.025,
+		CachedAudioInput: 0.175,
+		Output:           0.40,
+	},
+	ChatCompleteModelGemini2_0FlashLite: {
+		Input:  0.075,
+		Output: 0.30,
+	},
+	ChatCompleteModelGemini2_5Flash: {
+		Input:            0.30,
+		AudioInput:       1.00,
+		CachedInput:      0.03,
+		CachedAudioInput: 0.10,
+		Output:           2.50,
+	},
+	ChatCompleteModelGemini2_5FlashImage: {
+		Input:       0.30,
+		Output:      2.50,
+		ImageOutput: 30.00,
+	},
+	ChatCompleteModelGemini2_5FlashImagePreview: {
+		Input:       0.30,
+		Output:      2.50,
+		ImageOutput: 30.00,
+	},
+	ChatCompleteModelGemini2_5FlashLite: {
+		Input:            0.10,
+		AudioInput:       0.30,
+		CachedInput:      0.01,
+		CachedAudioInput: 0.03,
+		Output:           0.40,
+	},
+	ChatCompleteModelGemini2_5Pro: {
+		Input:                1.25,
+		CachedInput:          0.125,
+		Output:               10.00,
+		LongContextThreshold: 200_000,
+		LongContext: &ModelPricing{
+			Input:       2.50,
+			CachedInput: 0.25,
+			Output:      15.00,
+		},
+	},
+}
+
+// LookupPricing of a model in the table of default prices.
+func LookupPricing(model ChatCompleteModel) (ModelPricing, bool) {
+	p, ok := pricing[model]
+	return p, ok
+}
+
+// estimateCost of the token usage with the pricing.
+func estimateCost(p ModelPricing, usage UsageDetails) CostEstimate {
+	if p.LongContextThreshold > 0 && p.LongContext != nil && usage.PromptTokens.Total > p.LongContextThreshold {
+		p = *p.LongContext
+	}
+
+	cached := usage.CachedTokens.Total
+	cachedAudio := usage.CachedTokens.Audio
+	uncachedAudio := usage.PromptTokens.Audio - cachedAudio
+	uncached := usage.PromptTokens.Total - cached - uncachedAudio + usage.ToolUsePromptTokens.Total
+
+	imageOutput := usage.CompletionTokens.Image
+	textOutput := usage.CompletionTokens.Total - imageOutput
+
+	input := perMillion(uncached, p.Input) +
+		perMillion(uncachedAudio, cmp.Or(p.AudioInput, p.Input)) +
+		perMillion(cached-cachedAudio, p.CachedInput) +
+		perMillion(cachedAudio, cmp.Or(p.CachedAudioInput, p.CachedInput))
+
+	output := perMillion(textOutput, p.Output) +
+		perMillion(imageOutput, cmp.Or(p.ImageOutput, p.Output)) +
+		perMillion(usage.ThoughtsTokens, cmp.Or(p.Thinking, p.Output))
+
+	return CostEstimate{
+		Input:  input,
+		Output: output,
+		Total:  input + output,
+	}
+}
+
+func perMillion(tokens int, price float64) float64 {
+	return float64(tokens) * price / 1_000_000
+}
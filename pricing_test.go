@@ -0,0 +1,77 @@
+package google_test
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestEstimateCost(t *testing.T) {
+	t.Run("prices text input, output, and thinking tokens", func(t *testing.T) {
+		cost := google.EstimateCost(google.ModelPricing{Input: 1, Output: 2, Thinking: 3}, google.ConvertUsage(&genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     1_000_000,
+			CandidatesTokenCount: 2_000_000,
+			ThoughtsTokenCount:   1_000_000,
+		}))
+
+		is.Equal(t, 1.0, cost.Input)
+		is.Equal(t, 7.0, cost.Output)
+		is.Equal(t, 8.0, cost.Total)
+	})
+
+	t.Run("prices audio, cached, and tool use tokens separately", func(t *testing.T) {
+		cost := google.EstimateCost(google.ModelPricing{Input: 1, AudioInput: 10, CachedInput: 0.1, CachedAudioInput: 1}, google.ConvertUsage(&genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount: 4_000_000,
+			PromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 2_000_000},
+				{Modality: genai.MediaModalityAudio, TokenCount: 2_000_000},
+			},
+			CachedContentTokenCount: 2_000_000,
+			CacheTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 1_000_000},
+				{Modality: genai.MediaModalityAudio, TokenCount: 1_000_000},
+			},
+			ToolUsePromptTokenCount: 1_000_000,
+		}))
+
+		// 1M uncached text and 1M tool use at 1, 1M uncached audio at 10, 1M cached text at 0.1, 1M cached audio at 1
+		is.Equal(t, 13.1, cost.Input)
+	})
+
+	t.Run("falls back to text prices for audio, image, and thinking tokens", func(t *testing.T) {
+		cost := google.EstimateCost(google.ModelPricing{Input: 1, Output: 2}, google.ConvertUsage(&genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount: 1_000_000,
+			PromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityAudio, TokenCount: 1_000_000},
+			},
+			CandidatesTokenCount: 1_000_000,
+			CandidatesTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityImage, TokenCount: 1_000_000},
+			},
+			ThoughtsTokenCount: 1_000_000,
+		}))
+
+		is.Equal(t, 1.0, cost.Input)
+		is.Equal(t, 4.0, cost.Output)
+	})
+
+	t.Run("uses long context prices above the threshold", func(t *testing.T) {
+		p, ok := google.LookupPricing(google.ChatCompleteModelGemini2_5Pro)
+		is.True(t, ok, "should have pricing")
+
+		cost := google.EstimateCost(p, google.ConvertUsage(&genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     200_000,
+			CandidatesTokenCount: 100_000,
+		}))
+		is.Equal(t, 0.25+1.0, cost.Total)
+
+		cost = google.EstimateCost(p, google.ConvertUsage(&genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     400_000,
+			CandidatesTokenCount: 100_000,
+		}))
+		is.Equal(t, 1.0+1.5, cost.Total)
+	})
+}
@@ -0,0 +1,92 @@
+package google
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// retryLogTransport logs retries of HTTP requests by the genai SDK, see [NewClientOptions.RetryOptions].
+// It only tracks requests with a context from [trackRetries], so it keeps no state of its own.
+type retryLogTransport struct {
+	log  *slog.Logger
+	next http.RoundTripper
+}
+
+// newRetryLogTransport wrapping next, defaulting to [http.DefaultTransport].
+func newRetryLogTransport(next http.RoundTripper, log *slog.Logger) *retryLogTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryLogTransport{log: log, next: next}
+}
+
+func (t *retryLogTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tracker, ok := req.Context().Value(retryTrackerKey{}).(*retryTracker)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	if attempt, reason, retry := tracker.start(req); retry {
+		logWithTrace(req.Context(), t.log).Info("Retrying request",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"attempt", attempt,
+			"reason", reason,
+		)
+	}
+
+	res, err := t.next.RoundTrip(req)
+	tracker.end(res, err)
+
+	return res, err
+}
+
+// retryTracker tracks the attempts of the latest request made with a context.
+// The SDK sends the same request again when retrying, and the requests of one call to the SDK are sequential,
+// so a different request is a new one, not a retry.
+type retryTracker struct {
+	attempt int
+	lock    sync.Mutex
+	reason  string
+	req     *http.Request
+}
+
+type retryTrackerKey struct{}
+
+// trackRetries of requests made with the returned context, so [retryLogTransport] can log them.
+// Use it for each call to the API. The state is released with the context.
+func trackRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryTrackerKey{}, &retryTracker{})
+}
+
+// start an attempt of the request, returning the attempt number, the reason the previous attempt failed,
+// and whether it's a retry.
+func (t *retryTracker) start(req *http.Request) (int, string, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.req != req {
+		t.req = req
+		t.attempt = 1
+		t.reason = ""
+		return t.attempt, "", false
+	}
+
+	t.attempt++
+	return t.attempt, t.reason, true
+}
+
+// end the current attempt with its result.
+func (t *retryTracker) end(res *http.Response, err error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch {
+	case err != nil:
+		t.reason = err.Error()
+	default:
+		t.reason = res.Status
+	}
+}
@@ -0,0 +1,196 @@
+package google
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+// SpanAttributeMode selects the attribute keys on spans.
+type SpanAttributeMode string
+
+const (
+	// SpanAttributeModeLegacy uses the "ai.*" attribute keys of this package. It's the default.
+	SpanAttributeModeLegacy = SpanAttributeMode("legacy")
+
+	// SpanAttributeModeGenAI uses the OpenTelemetry semantic conventions for generative AI,
+	// like "gen_ai.request.model" and "gen_ai.usage.input_tokens", and names spans like "chat gemini-2.5-flash".
+	SpanAttributeModeGenAI = SpanAttributeMode("gen_ai")
+
+	// SpanAttributeModeBoth uses both the legacy keys and the semantic conventions, for example while migrating dashboards.
+	// Spans keep their legacy names.
+	SpanAttributeModeBoth = SpanAttributeMode("both")
+)
+
+func (m SpanAttributeMode) legacy() bool {
+	return m != SpanAttributeModeGenAI
+}
+
+func (m SpanAttributeMode) genAI() bool {
+	return m == SpanAttributeModeGenAI || m == SpanAttributeModeBoth
+}
+
+// attributes for the mode, from the legacy attributes and the semantic convention attributes.
+func (m SpanAttributeMode) attributes(legacy, genAI []attribute.KeyValue) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if m.legacy() {
+		attrs = append(attrs, legacy...)
+	}
+	if m.genAI() {
+		attrs = append(attrs, genAI...)
+	}
+	return attrs
+}
+
+// spanName for the mode, which is "{operation} {model}" with the semantic conventions.
+func (m SpanAttributeMode) spanName(legacy, operation, model string) string {
+	if m == SpanAttributeModeGenAI {
+		return operation + " " + strings.TrimPrefix(model, "models/")
+	}
+	return legacy
+}
+
+// spanAttributes sets attributes on a span according to the mode.
+type spanAttributes struct {
+	mode SpanAttributeMode
+	span trace.Span
+}
+
+func (s spanAttributes) legacy(attrs ...attribute.KeyValue) {
+	if s.mode.legacy() {
+		s.span.SetAttributes(attrs...)
+	}
+}
+
+func (s spanAttributes) genAI(attrs ...attribute.KeyValue) {
+	if s.mode.genAI() {
+		s.span.SetAttributes(attrs...)
+	}
+}
+
+// genAISystem is the gen_ai.system value for the backend of the client.
+func genAISystem(client *genai.Client) string {
+	if isVertexAI(client) {
+		return "gcp.vertex_ai"
+	}
+	return "gcp.gemini"
+}
+
+// genAIAttributes common to spans and metrics of an operation with a model.
+func genAIAttributes(client *genai.Client, operation string, model string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("gen_ai.operation.name", operation),
+		attribute.String("gen_ai.system", genAISystem(client)),
+		attribute.String("gen_ai.request.model", strings.TrimPrefix(model, "models/")),
+	}
+}
+
+// messageEventBody is the body of a message event, like "gen_ai.user.message".
+type messageEventBody struct {
+	Content   string              `json:"content,omitempty"`
+	ID        string              `json:"id,omitempty"`
+	ToolCalls []toolCallEventBody `json:"tool_calls,omitempty"`
+}
+
+type toolCallEventBody struct {
+	ID       string                    `json:"id"`
+	Type     string                    `json:"type"`
+	Function toolCallFunctionEventBody `json:"function"`
+}
+
+type toolCallFunctionEventBody struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// choiceEventBody is the body of the "gen_ai.choice" event.
+type choiceEventBody struct {
+	Index        int              `json:"index"`
+	FinishReason string           `json:"finish_reason,omitempty"`
+	Message      messageEventBody `json:"message"`
+}
+
+// addMessageEvents to the span, one per system prompt, user message, model message, and tool result in the request.
+// Content is only included if the policy allows it, and data parts are left out.
+func addMessageEvents(span trace.Span, system string, policy contentPolicy, req ChatCompleteRequest) {
+	if req.System != nil {
+		content, _ := policy.system(*req.System)
+		addEvent(span, system, "gen_ai.system.message", messageEventBody{Content: content})
+	}
+
+	for _, m := range req.Messages {
+		var body messageEventBody
+		var text strings.Builder
+		for _, part := range m.Parts {
+			switch part.Type {
+			case gai.MessagePartTypeText:
+				text.WriteString(part.Text())
+
+			case gai.MessagePartTypeToolCall:
+				body.ToolCalls = append(body.ToolCalls, newToolCallEventBody(policy, part.ToolCall()))
+
+			case gai.MessagePartTypeToolResult:
+				result := part.ToolResult()
+				content := result.Content
+				if result.Err != nil {
+					content = result.Err.Error()
+				}
+				content, _ = policy.full(content)
+				addEvent(span, system, "gen_ai.tool.message", messageEventBody{Content: content, ID: result.ID})
+			}
+		}
+
+		if text.Len() == 0 && len(body.ToolCalls) == 0 {
+			continue
+		}
+		body.Content, _ = policy.full(text.String())
+
+		name := "gen_ai.user.message"
+		if m.Role == gai.MessageRoleModel {
+			name = "gen_ai.assistant.message"
+		}
+		addEvent(span, system, name, body)
+	}
+}
+
+// genAIFinishReason in lowercase, like "stop", as the semantic conventions use.
+func genAIFinishReason(reason genai.FinishReason) string {
+	return strings.ToLower(string(reason))
+}
+
+// addChoiceEvent to the span, with the complete response. Content is only included if the policy allows it.
+func addChoiceEvent(span trace.Span, system string, policy contentPolicy, finishReason genai.FinishReason, text string, toolCalls []gai.ToolCall) {
+	body := choiceEventBody{
+		FinishReason: genAIFinishReason(finishReason),
+	}
+	body.Message.Content, _ = policy.full(text)
+	for _, call := range toolCalls {
+		body.Message.ToolCalls = append(body.Message.ToolCalls, newToolCallEventBody(policy, call))
+	}
+	addEvent(span, system, "gen_ai.choice", body)
+}
+
+func newToolCallEventBody(policy contentPolicy, call gai.ToolCall) toolCallEventBody {
+	args, _ := policy.full(string(call.Args))
+	return toolCallEventBody{
+		ID:       call.ID,
+		Type:     "function",
+		Function: toolCallFunctionEventBody{Name: call.Name, Arguments: args},
+	}
+}
+
+func addEvent(span trace.Span, system string, name string, body any) {
+	content, err := json.Marshal(body)
+	if err != nil {
+		// The bodies only contain strings, so this doesn't happen
+		return
+	}
+	span.AddEvent(name, trace.WithAttributes(
+		attribute.String("gen_ai.system", system),
+		attribute.String("gen_ai.event.content", string(content)),
+	))
+}
@@ -0,0 +1,180 @@
+package google_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+const toolCallStream = `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Let me check."}]}}],"usageMetadata":{"promptTokenCount":10,"totalTokenCount":10},"modelVersion":"gemini-2.5-flash","responseId":"abc123"}
+
+data: {"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Copenhagen"}}}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":5,"thoughtsTokenCount":3,"totalTokenCount":18},"modelVersion":"gemini-2.5-flash","responseId":"abc123"}
+
+`
+
+func TestChatCompleter_ChatComplete_SpanAttributes(t *testing.T) {
+	t.Run("uses the GenAI semantic conventions and adds message events", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:           newChatServer(t, http.StatusOK, toolCallStream),
+			ContentCapture:    google.ContentCaptureFull,
+			Key:               "test",
+			MessageEvents:     true,
+			SpanAttributeMode: google.SpanAttributeModeGenAI,
+			TracerProvider:    tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("What's the weather in Copenhagen?")},
+			System:   gai.Ptr("You are a weather assistant."),
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		span := spans[0]
+		is.Equal(t, "chat gemini-2.5-flash", span.Name())
+
+		attrs := attributeMap(span.Attributes())
+		is.Equal(t, "chat", attrs["gen_ai.operation.name"].AsString())
+		is.Equal(t, "gcp.gemini", attrs["gen_ai.system"].AsString())
+		is.Equal(t, "gemini-2.5-flash", attrs["gen_ai.request.model"].AsString())
+		is.Equal(t, "gemini-2.5-flash", attrs["gen_ai.response.model"].AsString())
+		is.Equal(t, "abc123", attrs["gen_ai.response.id"].AsString())
+		is.Equal(t, int64(10), attrs["gen_ai.usage.input_tokens"].AsInt64())
+		is.Equal(t, int64(8), attrs["gen_ai.usage.output_tokens"].AsInt64())
+		is.EqualSlice(t, []string{"stop"}, attrs["gen_ai.response.finish_reasons"].AsStringSlice())
+
+		_, ok := attrs["ai.model"]
+		is.True(t, !ok, "should not have legacy attributes")
+
+		events := messageEvents(span)
+		is.Equal(t, 3, len(events))
+		is.Equal(t, "gen_ai.system.message", events[0].Name)
+		is.Equal(t, `{"content":"You are a weather assistant."}`, attributeMap(events[0].Attributes)["gen_ai.event.content"].AsString())
+		is.Equal(t, "gen_ai.user.message", events[1].Name)
+		is.Equal(t, `{"content":"What's the weather in Copenhagen?"}`, attributeMap(events[1].Attributes)["gen_ai.event.content"].AsString())
+		is.Equal(t, "gen_ai.choice", events[2].Name)
+
+		var choice struct {
+			FinishReason string `json:"finish_reason"`
+			Message      struct {
+				Content   string
+				ToolCalls []struct {
+					ID       string
+					Function struct {
+						Name      string
+						Arguments string
+					}
+				} `json:"tool_calls"`
+			}
+		}
+		err := json.Unmarshal([]byte(attributeMap(events[2].Attributes)["gen_ai.event.content"].AsString()), &choice)
+		is.NotError(t, err)
+		is.Equal(t, "stop", choice.FinishReason)
+		is.Equal(t, "Let me check.", choice.Message.Content)
+		is.Equal(t, 1, len(choice.Message.ToolCalls))
+		is.True(t, choice.Message.ToolCalls[0].ID != "", "should have tool call ID")
+		is.Equal(t, "get_weather", choice.Message.ToolCalls[0].Function.Name)
+		is.Equal(t, `{"city":"Copenhagen"}`, choice.Message.ToolCalls[0].Function.Arguments)
+	})
+
+	t.Run("uses legacy attributes without message events by default", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{BaseURL: newChatServer(t, http.StatusOK, toolCallStream), Key: "test", TracerProvider: tp})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("What's the weather in Copenhagen?")},
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		is.Equal(t, "google.chat_complete", spans[0].Name())
+
+		attrs := attributeMap(spans[0].Attributes())
+		is.Equal(t, string(google.ChatCompleteModelGemini2_5Flash), attrs["ai.model"].AsString())
+		is.Equal(t, int64(10), attrs["ai.prompt_tokens"].AsInt64())
+
+		_, ok := attrs["gen_ai.request.model"]
+		is.True(t, !ok, "should not have semantic convention attributes")
+		is.Equal(t, 0, len(messageEvents(spans[0])))
+	})
+
+	t.Run("uses both and records the error type", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL:           newChatServer(t, http.StatusTooManyRequests, `{"error":{"code":429,"message":"Resource exhausted.","status":"RESOURCE_EXHAUSTED"}}`),
+			Key:               "test",
+			SpanAttributeMode: google.SpanAttributeModeBoth,
+			TracerProvider:    tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Hi!")},
+		})
+		is.NotError(t, err)
+		for range res.Parts() {
+		}
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		is.Equal(t, "google.chat_complete", spans[0].Name())
+
+		attrs := attributeMap(spans[0].Attributes())
+		is.Equal(t, string(google.ChatCompleteModelGemini2_5Flash), attrs["ai.model"].AsString())
+		is.Equal(t, "gemini-2.5-flash", attrs["gen_ai.request.model"].AsString())
+		is.Equal(t, "429", attrs["error.type"].AsString())
+	})
+}
+
+// newSpanRecorder and a tracer provider recording to it, for [google.NewClientOptions.TracerProvider].
+func newSpanRecorder(t *testing.T) (*tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	return recorder, sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+}
+
+// completeAll parts of the response, failing on errors.
+func completeAll(t *testing.T, cc *google.ChatCompleter, req gai.ChatCompleteRequest) {
+	t.Helper()
+
+	res, err := cc.ChatComplete(t.Context(), req)
+	is.NotError(t, err)
+	for _, err := range res.Parts() {
+		is.NotError(t, err)
+	}
+}
+
+// messageEvents of the span, like "gen_ai.user.message", leaving out other events.
+func messageEvents(span sdktrace.ReadOnlySpan) []sdktrace.Event {
+	var events []sdktrace.Event
+	for _, e := range span.Events() {
+		if strings.HasPrefix(e.Name, "gen_ai.") {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func attributeMap(attrs []attribute.KeyValue) map[string]attribute.Value {
+	m := map[string]attribute.Value{}
+	for _, a := range attrs {
+		m[string(a.Key)] = a.Value
+	}
+	return m
+}
@@ -0,0 +1,202 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+type SpeechModel string
+
+const (
+	SpeechModelGemini2_5FlashTTS = SpeechModel("models/gemini-2.5-flash-preview-tts")
+	SpeechModelGemini2_5ProTTS   = SpeechModel("models/gemini-2.5-pro-preview-tts")
+)
+
+// SpeechSynthesizer turns text into speech with Gemini TTS models.
+// For spoken responses in a conversation, you can also use [NewChatCompleterOptions.ResponseModalities]
+// with [genai.ModalityAudio] on models supporting it.
+type SpeechSynthesizer struct {
+	Client *genai.Client
+	log    *slog.Logger
+	model  SpeechModel
+	tracer trace.Tracer
+}
+
+type NewSpeechSynthesizerOptions struct {
+	// Model defaults to the default model of [ModelFamilySpeech], see [ModelSpecs].
+	Model SpeechModel
+}
+
+func (c *Client) NewSpeechSynthesizer(opts NewSpeechSynthesizerOptions) *SpeechSynthesizer {
+	if opts.Model == "" {
+		opts.Model = defaultModel[SpeechModel](ModelFamilySpeech)
+	}
+
+	return &SpeechSynthesizer{
+		Client: c.Client,
+		log:    c.log,
+		model:  opts.Model,
+		tracer: c.tracer,
+	}
+}
+
+// SynthesizeSpeechRequest for [SpeechSynthesizer.SynthesizeSpeech].
+type SynthesizeSpeechRequest struct {
+	// LanguageCode of the speech, for example "en-US". Optional.
+	LanguageCode string
+
+	// Speakers for multi-speaker speech. The speaker names must match the speaker labels in the text,
+	// for example "Joe: How's it going today Jane?". Mutually exclusive with Voice.
+	Speakers []SpeakerVoice
+
+	// Text to speak. It can include instructions on how to speak, for example "Say cheerfully: Have a wonderful day!".
+	Text string
+
+	// Voice is the name of a prebuilt voice, for example "Kore" or "Puck". Optional.
+	Voice string
+}
+
+// SpeakerVoice for a speaker in multi-speaker speech.
+type SpeakerVoice struct {
+	Speaker string
+	Voice   string
+}
+
+// SynthesizeSpeechResponse from [SpeechSynthesizer.SynthesizeSpeech].
+type SynthesizeSpeechResponse struct {
+	// PCM audio, 16-bit signed little-endian mono.
+	PCM []byte
+
+	// SampleRate of the audio in Hz.
+	SampleRate int
+}
+
+// WAV returns the audio wrapped in a WAV container.
+func (r SynthesizeSpeechResponse) WAV() []byte {
+	return PCMToWAV(r.PCM, r.SampleRate, 1, 16)
+}
+
+// Part returns the audio as a [gai.MessagePartTypeData] part with WAV data.
+func (r SynthesizeSpeechResponse) Part() gai.MessagePart {
+	return gai.DataMessagePart("audio/wav", bytes.NewReader(r.WAV()))
+}
+
+// SynthesizeSpeech from the request text.
+func (s *SpeechSynthesizer) SynthesizeSpeech(ctx context.Context, req SynthesizeSpeechRequest) (SynthesizeSpeechResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "google.synthesize_speech",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(s.model)),
+		),
+	)
+	defer span.End()
+
+	log := logWithTrace(ctx, s.log).With("model", s.model)
+
+	if req.Voice != "" && len(req.Speakers) > 0 {
+		err := fmt.Errorf("voice and speakers are mutually exclusive")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid speech request")
+		return SynthesizeSpeechResponse{}, err
+	}
+
+	speechConfig := &genai.SpeechConfig{
+		LanguageCode: req.LanguageCode,
+	}
+	if req.Voice != "" {
+		speechConfig.VoiceConfig = newVoiceConfig(req.Voice)
+		span.SetAttributes(attribute.String("ai.voice", req.Voice))
+	}
+	if len(req.Speakers) > 0 {
+		speechConfig.MultiSpeakerVoiceConfig = &genai.MultiSpeakerVoiceConfig{}
+		var voices []string
+		for _, speaker := range req.Speakers {
+			speechConfig.MultiSpeakerVoiceConfig.SpeakerVoiceConfigs = append(speechConfig.MultiSpeakerVoiceConfig.SpeakerVoiceConfigs,
+				&genai.SpeakerVoiceConfig{
+					Speaker:     speaker.Speaker,
+					VoiceConfig: newVoiceConfig(speaker.Voice),
+				})
+			voices = append(voices, speaker.Voice)
+		}
+		span.SetAttributes(
+			attribute.Int("ai.speaker_count", len(req.Speakers)),
+			attribute.StringSlice("ai.voices", voices),
+		)
+	}
+	if req.LanguageCode != "" {
+		span.SetAttributes(attribute.String("ai.language_code", req.LanguageCode))
+	}
+
+	config := genai.GenerateContentConfig{
+		ResponseModalities: []string{string(genai.ModalityAudio)},
+		SpeechConfig:       speechConfig,
+	}
+
+	log.Debug("Sending speech synthesis request", "text_length", len(req.Text), "speaker_count", len(req.Speakers))
+
+	res, err := s.Client.Models.GenerateContent(trackRetries(ctx), modelName(s.Client, s.model), genai.Text(req.Text), &config)
+	if err != nil {
+		log.Info("Speech synthesis request failed", "error", err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "speech synthesis failed")
+		return SynthesizeSpeechResponse{}, err
+	}
+
+	var audio *genai.Blob
+	if len(res.Candidates) > 0 && res.Candidates[0].Content != nil {
+		for _, part := range res.Candidates[0].Content.Parts {
+			if part.InlineData != nil {
+				audio = part.InlineData
+				break
+			}
+		}
+	}
+	if audio == nil {
+		err := fmt.Errorf("no audio in response")
+		log.Info("Speech synthesis response had no audio")
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "no audio in response")
+		return SynthesizeSpeechResponse{}, err
+	}
+
+	sampleRate := sampleRateFromMIMEType(audio.MIMEType)
+	span.SetAttributes(
+		attribute.Int("ai.sample_rate", sampleRate),
+		attribute.Int("ai.audio_bytes", len(audio.Data)),
+	)
+	log.Debug("Synthesized speech", "sample_rate", sampleRate, "audio_bytes", len(audio.Data))
+
+	return SynthesizeSpeechResponse{
+		PCM:        audio.Data,
+		SampleRate: sampleRate,
+	}, nil
+}
+
+func newVoiceConfig(voice string) *genai.VoiceConfig {
+	return &genai.VoiceConfig{
+		PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: voice},
+	}
+}
+
+// sampleRateFromMIMEType like "audio/L16;codec=pcm;rate=24000", defaulting to the 24 kHz Gemini TTS models use.
+func sampleRateFromMIMEType(mimeType string) int {
+	_, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return 24000
+	}
+	rate, err := strconv.Atoi(params["rate"])
+	if err != nil || rate <= 0 {
+		return 24000
+	}
+	return rate
+}
@@ -0,0 +1,64 @@
+package google_test
+
+import (
+	"testing"
+
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestSpeechSynthesizer_SynthesizeSpeech(t *testing.T) {
+	t.Run("can synthesize speech with a voice", func(t *testing.T) {
+		ss := newSpeechSynthesizer(t)
+
+		res, err := ss.SynthesizeSpeech(t.Context(), google.SynthesizeSpeechRequest{
+			Text:  "Say cheerfully: Have a wonderful day!",
+			Voice: "Kore",
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, 24000, res.SampleRate)
+		is.True(t, len(res.PCM) > 0, "should have audio")
+		is.Equal(t, "RIFF", string(res.WAV()[:4]))
+
+		part := res.Part()
+		is.Equal(t, gai.MessagePartTypeData, part.Type)
+		is.Equal(t, "audio/wav", part.MIMEType)
+	})
+
+	t.Run("can synthesize speech with multiple speakers", func(t *testing.T) {
+		ss := newSpeechSynthesizer(t)
+
+		res, err := ss.SynthesizeSpeech(t.Context(), google.SynthesizeSpeechRequest{
+			Speakers: []google.SpeakerVoice{
+				{Speaker: "Joe", Voice: "Kore"},
+				{Speaker: "Jane", Voice: "Puck"},
+			},
+			Text: "TTS the following conversation between Joe and Jane:\nJoe: How's it going today Jane?\nJane: Not too bad, how about you?",
+		})
+		is.NotError(t, err)
+
+		is.True(t, len(res.PCM) > 0, "should have audio")
+	})
+
+	t.Run("errors with both voice and speakers", func(t *testing.T) {
+		c := google.NewClient(google.NewClientOptions{Key: "invalid"})
+		ss := c.NewSpeechSynthesizer(google.NewSpeechSynthesizerOptions{Model: google.SpeechModelGemini2_5FlashTTS})
+
+		_, err := ss.SynthesizeSpeech(t.Context(), google.SynthesizeSpeechRequest{
+			Speakers: []google.SpeakerVoice{{Speaker: "Joe", Voice: "Kore"}},
+			Text:     "Joe: Hi!",
+			Voice:    "Kore",
+		})
+		is.True(t, err != nil, "should error")
+	})
+}
+
+func newSpeechSynthesizer(t *testing.T) *google.SpeechSynthesizer {
+	c := newClient(t)
+	return c.NewSpeechSynthesizer(google.NewSpeechSynthesizerOptions{
+		Model: google.SpeechModelGemini2_5FlashTTS,
+	})
+}
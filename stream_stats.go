@@ -0,0 +1,53 @@
+package google
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// streamStats about the timing of chunks in a response stream.
+type streamStats struct {
+	chunks    int
+	lastChunk time.Time
+	minGap    time.Duration
+	maxGap    time.Duration
+	totalGap  time.Duration
+}
+
+// chunk received at the given time. It returns whether it's the first chunk.
+func (s *streamStats) chunk(at time.Time) bool {
+	s.chunks++
+	if s.chunks == 1 {
+		s.lastChunk = at
+		return true
+	}
+
+	gap := at.Sub(s.lastChunk)
+	s.lastChunk = at
+	if s.chunks == 2 || gap < s.minGap {
+		s.minGap = gap
+	}
+	s.maxGap = max(s.maxGap, gap)
+	s.totalGap += gap
+	return false
+}
+
+// attributes with the chunk count, and the gaps between chunks in milliseconds if there's more than one chunk.
+func (s *streamStats) attributes() []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int("ai.chunk_count", s.chunks),
+	}
+	if s.chunks < 2 {
+		return attrs
+	}
+	return append(attrs,
+		attribute.Float64("ai.chunk_gap_min_ms", milliseconds(s.minGap)),
+		attribute.Float64("ai.chunk_gap_max_ms", milliseconds(s.maxGap)),
+		attribute.Float64("ai.chunk_gap_mean_ms", milliseconds(s.totalGap/time.Duration(s.chunks-1))),
+	)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,37 @@
+package google_test
+
+import (
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestStreamStats(t *testing.T) {
+	t.Run("has gap statistics between chunks", func(t *testing.T) {
+		var s google.StreamStats
+		start := time.Now()
+		is.True(t, s.Chunk(start), "should be first chunk")
+		is.True(t, !s.Chunk(start.Add(100*time.Millisecond)), "should not be first chunk")
+		is.True(t, !s.Chunk(start.Add(130*time.Millisecond)), "should not be first chunk")
+		is.True(t, !s.Chunk(start.Add(240*time.Millisecond)), "should not be first chunk")
+
+		is.EqualSlice(t, []attribute.KeyValue{
+			attribute.Int("ai.chunk_count", 4),
+			attribute.Float64("ai.chunk_gap_min_ms", 30),
+			attribute.Float64("ai.chunk_gap_max_ms", 110),
+			attribute.Float64("ai.chunk_gap_mean_ms", 80),
+		}, s.Attributes())
+	})
+
+	t.Run("has only the chunk count with fewer than two chunks", func(t *testing.T) {
+		var s google.StreamStats
+		is.EqualSlice(t, []attribute.KeyValue{attribute.Int("ai.chunk_count", 0)}, s.Attributes())
+
+		s.Chunk(time.Now())
+		is.EqualSlice(t, []attribute.KeyValue{attribute.Int("ai.chunk_count", 1)}, s.Attributes())
+	})
+}
@@ -0,0 +1,239 @@
+package google
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+// MessagePartTypeToolCallDelta is for partial tool call arguments streamed before the complete tool call part.
+// See [NewChatCompleterOptions.StreamToolCallArgs]. Read it with [ToolCallDeltaFromPart].
+// Parts of this type are skipped when sent back to the model.
+const MessagePartTypeToolCallDelta = gai.MessagePartType("tool_call_delta")
+
+// ToolCallDelta is a partial argument value of a tool call that is still being streamed.
+type ToolCallDelta struct {
+	// ID of the tool call, which is the same as in the complete tool call part.
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// JSONPath (RFC 9535) of the argument, for example "$.content" or "$.files[0].path".
+	JSONPath string `json:"jsonPath"`
+
+	// Value is a string, float64, bool, or nil.
+	// If the previous delta for the same path had WillContinue set, a string value continues that string.
+	Value any `json:"value"`
+
+	// WillContinue is set if more of the string value at the same path follows.
+	WillContinue bool `json:"willContinue"`
+}
+
+// ToolCallDeltaPart creates a [gai.MessagePart] of type [MessagePartTypeToolCallDelta].
+func ToolCallDeltaPart(delta ToolCallDelta) gai.MessagePart {
+	return jsonPart(MessagePartTypeToolCallDelta, delta)
+}
+
+// ToolCallDeltaFromPart reads the [ToolCallDelta] from a part of type [MessagePartTypeToolCallDelta].
+func ToolCallDeltaFromPart(part gai.MessagePart) (ToolCallDelta, error) {
+	var delta ToolCallDelta
+	if err := readJSONPart(part, MessagePartTypeToolCallDelta, &delta); err != nil {
+		return ToolCallDelta{}, err
+	}
+	return delta, nil
+}
+
+// isPartialFunctionCall returns whether the function call has streamed arguments, with more to follow.
+func isPartialFunctionCall(call *genai.FunctionCall) bool {
+	return len(call.PartialArgs) > 0 || (call.WillContinue != nil && *call.WillContinue)
+}
+
+// partialToolCall assembles the arguments of a function call streamed over several parts.
+type partialToolCall struct {
+	ID         string
+	Name       string
+	Args       map[string]any
+	callID     string
+	continuing map[string]bool
+	done       bool
+}
+
+// newPartialToolCall with the tool call ID, and the ID the model gave the call, which may be empty.
+func newPartialToolCall(id, callID, name string) *partialToolCall {
+	return &partialToolCall{
+		ID:         id,
+		Name:       name,
+		Args:       map[string]any{},
+		callID:     callID,
+		continuing: map[string]bool{},
+	}
+}
+
+// isOther returns whether the function call part belongs to another call than this one,
+// which is only valid once this one is done. Continuation parts have no ID or name.
+func (p *partialToolCall) isOther(call *genai.FunctionCall) bool {
+	return (call.ID != "" && call.ID != p.callID) || (call.Name != "" && p.Name != "" && call.Name != p.Name)
+}
+
+// functionCall with the arguments assembled so far.
+func (p *partialToolCall) functionCall() *genai.FunctionCall {
+	return &genai.FunctionCall{Name: p.Name, Args: p.Args}
+}
+
+// add the partial arguments from the function call part, returning the deltas.
+func (p *partialToolCall) add(call *genai.FunctionCall) ([]ToolCallDelta, error) {
+	if p.Name == "" {
+		p.Name = call.Name
+	}
+
+	var deltas []ToolCallDelta
+	for _, arg := range call.PartialArgs {
+		var value any
+		switch {
+		case arg.BoolValue != nil:
+			value = *arg.BoolValue
+		case arg.NumberValue != nil:
+			value = *arg.NumberValue
+		case arg.NULLValue != "":
+			value = nil
+		default:
+			value = arg.StringValue
+		}
+
+		path, err := parseJSONPath(arg.JsonPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(path) == 0 || path[0].isIndex {
+			return nil, fmt.Errorf("JSON path %q is not an argument", arg.JsonPath)
+		}
+		_, isString := value.(string)
+		args, err := setJSONPath(p.Args, path, value, isString && p.continuing[arg.JsonPath])
+		if err != nil {
+			return nil, fmt.Errorf("error setting JSON path %q: %w", arg.JsonPath, err)
+		}
+		p.Args = args.(map[string]any)
+
+		willContinue := arg.WillContinue != nil && *arg.WillContinue
+		p.continuing[arg.JsonPath] = willContinue
+
+		deltas = append(deltas, ToolCallDelta{
+			ID:           p.ID,
+			Name:         p.Name,
+			JSONPath:     arg.JsonPath,
+			Value:        value,
+			WillContinue: willContinue,
+		})
+	}
+
+	// Any complete arguments take precedence over the assembled ones
+	for k, v := range call.Args {
+		p.Args[k] = v
+	}
+
+	p.done = call.WillContinue == nil || !*call.WillContinue
+
+	return deltas, nil
+}
+
+// jsonPathSegment is either an object key or an array index.
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath in the simple form used for streamed arguments, like "$.a.b[0]['c']".
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSON path %q", path)
+	}
+
+	var segments []jsonPathSegment
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			segments = append(segments, jsonPathSegment{key: rest[:end]})
+			rest = rest[end:]
+
+		case strings.HasPrefix(rest, "['"):
+			end := strings.Index(rest, "']")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			segments = append(segments, jsonPathSegment{key: rest[2:end]})
+			rest = rest[end+2:]
+
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+	}
+
+	return segments, nil
+}
+
+// maxArrayGrowth is how far past the end of an array a streamed argument can set an element,
+// so a huge index from the model can't allocate a huge array.
+const maxArrayGrowth = 16
+
+// setJSONPath in v to value, creating objects and arrays as needed, and returning the updated v.
+// If appendString is set and there's already a string at the path, value is appended to it.
+func setJSONPath(v any, path []jsonPathSegment, value any, appendString bool) (any, error) {
+	if len(path) == 0 {
+		if existing, ok := v.(string); ok && appendString {
+			return existing + value.(string), nil
+		}
+		return value, nil
+	}
+
+	segment := path[0]
+	if segment.isIndex {
+		array, _ := v.([]any)
+		if segment.index >= len(array)+maxArrayGrowth {
+			return nil, fmt.Errorf("array index %v is too far past the array length %v", segment.index, len(array))
+		}
+		for len(array) <= segment.index {
+			array = append(array, nil)
+		}
+		element, err := setJSONPath(array[segment.index], path[1:], value, appendString)
+		if err != nil {
+			return nil, err
+		}
+		array[segment.index] = element
+		return array, nil
+	}
+
+	object, _ := v.(map[string]any)
+	if object == nil {
+		object = map[string]any{}
+	}
+	element, err := setJSONPath(object[segment.key], path[1:], value, appendString)
+	if err != nil {
+		return nil, err
+	}
+	object[segment.key] = element
+	return object, nil
+}
@@ -0,0 +1,154 @@
+package google_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestChatCompleter_ChatComplete_StreamToolCallArgs(t *testing.T) {
+	t.Run("streams deltas and assembles the tool call", func(t *testing.T) {
+		deltas, toolCalls, err := streamToolCallArgs(t,
+			`{"functionCall":{"name":"write_file","partialArgs":[{"jsonPath":"$.path","stringValue":"readme.txt"},{"jsonPath":"$.content","stringValue":"Hello, ","willContinue":true}],"willContinue":true}}`,
+			`{"functionCall":{"partialArgs":[{"jsonPath":"$.content","stringValue":"world!"},{"jsonPath":"$.options.overwrite","boolValue":true},{"jsonPath":"$.tags[1]","numberValue":2}],"willContinue":true}}`,
+			`{"functionCall":{}}`,
+		)
+		is.NotError(t, err)
+
+		is.Equal(t, 5, len(deltas))
+		is.Equal(t, "write_file", deltas[1].Name)
+		is.Equal(t, "$.content", deltas[1].JSONPath)
+		is.Equal[any](t, "Hello, ", deltas[1].Value)
+		is.True(t, deltas[1].WillContinue, "should continue")
+		is.Equal[any](t, true, deltas[3].Value)
+
+		is.Equal(t, 1, len(toolCalls))
+		is.Equal(t, deltas[0].ID, toolCalls[0].ID)
+		is.Equal(t, "write_file", toolCalls[0].Name)
+		is.Equal(t, `{"content":"Hello, world!","options":{"overwrite":true},"path":"readme.txt","tags":[null,2]}`, string(toolCalls[0].Args))
+	})
+
+	t.Run("starts a new tool call after the previous one is done", func(t *testing.T) {
+		deltas, toolCalls, err := streamToolCallArgs(t,
+			`{"functionCall":{"id":"1","name":"read_file","partialArgs":[{"jsonPath":"$.path","stringValue":"readme.txt"}],"willContinue":true}}`,
+			`{"functionCall":{}}`,
+			`{"functionCall":{"id":"2","name":"list_dir","partialArgs":[{"jsonPath":"$.path","stringValue":"."}],"willContinue":true}}`,
+			`{"functionCall":{}}`,
+		)
+		is.NotError(t, err)
+
+		is.Equal(t, 2, len(deltas))
+		is.Equal(t, 2, len(toolCalls))
+		is.Equal(t, "1", toolCalls[0].ID)
+		is.Equal(t, "read_file", toolCalls[0].Name)
+		is.Equal(t, `{"path":"readme.txt"}`, string(toolCalls[0].Args))
+		is.Equal(t, "2", toolCalls[1].ID)
+		is.Equal(t, "list_dir", toolCalls[1].Name)
+		is.Equal(t, `{"path":"."}`, string(toolCalls[1].Args))
+		is.Equal(t, toolCalls[1].ID, deltas[1].ID)
+	})
+
+	t.Run("errors if another tool call cuts off an incomplete tool call", func(t *testing.T) {
+		tests := []struct {
+			name string
+			next string
+		}{
+			{"by name", `{"functionCall":{"name":"list_dir","partialArgs":[{"jsonPath":"$.path","stringValue":"."}]}}`},
+			{"by ID", `{"functionCall":{"id":"2","name":"read_file","partialArgs":[{"jsonPath":"$.path","stringValue":"logo.jpg"}]}}`},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				deltas, toolCalls, err := streamToolCallArgs(t,
+					`{"functionCall":{"id":"1","name":"read_file","partialArgs":[{"jsonPath":"$.path","stringValue":"readme.txt"}],"willContinue":true}}`,
+					test.next,
+				)
+				is.True(t, err != nil, "should error")
+				is.True(t, strings.Contains(err.Error(), "cut off"), err.Error())
+				is.Equal(t, 1, len(deltas))
+				is.Equal(t, 0, len(toolCalls))
+			})
+		}
+	})
+
+	t.Run("errors if the stream ends with an incomplete tool call", func(t *testing.T) {
+		deltas, toolCalls, err := streamToolCallArgs(t,
+			`{"functionCall":{"name":"write_file","partialArgs":[{"jsonPath":"$.content","stringValue":"Hello, ","willContinue":true}],"willContinue":true}}`,
+		)
+		is.True(t, err != nil, "should error")
+		is.True(t, strings.Contains(err.Error(), "incomplete args"), err.Error())
+		is.Equal(t, 1, len(deltas))
+		is.Equal(t, 0, len(toolCalls))
+	})
+
+	t.Run("errors on invalid JSON paths and huge array indexes", func(t *testing.T) {
+		for _, path := range []string{"", "content", "$", "$[0]", "$.", "$.a[b]", "$['a", "$.a[999999999999]"} {
+			t.Run(path, func(t *testing.T) {
+				_, toolCalls, err := streamToolCallArgs(t,
+					fmt.Sprintf(`{"functionCall":{"name":"write_file","partialArgs":[{"jsonPath":%q,"stringValue":"a"}],"willContinue":true}}`, path),
+				)
+				is.True(t, err != nil, "should error")
+				is.Equal(t, 0, len(toolCalls))
+			})
+		}
+	})
+}
+
+func TestToolCallDeltaFromPart(t *testing.T) {
+	t.Run("can read a tool call delta from a part", func(t *testing.T) {
+		part := google.ToolCallDeltaPart(google.ToolCallDelta{ID: "1", Name: "write_file", JSONPath: "$.content", Value: "Hi", WillContinue: true})
+		is.Equal(t, google.MessagePartTypeToolCallDelta, part.Type)
+
+		delta, err := google.ToolCallDeltaFromPart(part)
+		is.NotError(t, err)
+		is.Equal(t, "1", delta.ID)
+		is.Equal(t, "$.content", delta.JSONPath)
+		is.Equal[any](t, "Hi", delta.Value)
+		is.True(t, delta.WillContinue, "should continue")
+	})
+}
+
+// streamToolCallArgs from a Vertex AI stand-in sending each part in its own chunk,
+// returning the deltas and tool calls received, and the stream error if any.
+func streamToolCallArgs(t *testing.T, parts ...string) ([]google.ToolCallDelta, []gai.ToolCall, error) {
+	t.Helper()
+
+	var body strings.Builder
+	for _, part := range parts {
+		body.WriteString(`data: {"candidates":[{"content":{"role":"model","parts":[` + part + `]}}]}` + "\n\n")
+	}
+
+	c := newVertexStandInClient(t, newChatServer(t, http.StatusOK, body.String()))
+	cc := c.NewChatCompleter(google.NewChatCompleterOptions{
+		Model:              google.ChatCompleteModelGemini2_5Flash,
+		StreamToolCallArgs: true,
+	})
+
+	res, err := cc.ChatComplete(t.Context(), gai.ChatCompleteRequest{
+		Messages: []gai.Message{gai.NewUserTextMessage("Write a file.")},
+	})
+	is.NotError(t, err)
+
+	var deltas []google.ToolCallDelta
+	var toolCalls []gai.ToolCall
+	for part, err := range res.Parts() {
+		if err != nil {
+			return deltas, toolCalls, err
+		}
+
+		switch part.Type {
+		case google.MessagePartTypeToolCallDelta:
+			delta, err := google.ToolCallDeltaFromPart(part)
+			is.NotError(t, err)
+			deltas = append(deltas, delta)
+		case gai.MessagePartTypeToolCall:
+			toolCalls = append(toolCalls, part.ToolCall())
+		}
+	}
+	return deltas, toolCalls, nil
+}
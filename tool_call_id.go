@@ -0,0 +1,47 @@
+package google
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+)
+
+// IDGenerator generates tool call IDs, used when the model doesn't provide one.
+// It must return a different ID on each call, and be safe for concurrent use.
+type IDGenerator func() string
+
+// RandomID returns a random 128-bit ID from a cryptographically secure source, hex-encoded.
+// This is the default [IDGenerator].
+func RandomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // Never returns an error
+	return fmt.Sprintf("%x", b)
+}
+
+// NewCounterIDGenerator returns an [IDGenerator] which returns the prefix followed by an increasing counter,
+// starting at 1. This is useful for deterministic IDs in tests.
+func NewCounterIDGenerator(prefix string) IDGenerator {
+	var counter atomic.Uint64
+	return func() string {
+		return fmt.Sprintf("%v%v", prefix, counter.Add(1))
+	}
+}
+
+// maxIDAttempts is the number of IDs to try before giving up on finding a unique one, so a broken [IDGenerator]
+// can't loop forever.
+const maxIDAttempts = 100
+
+// toolCallIDs seen so far, used to keep tool call IDs unique, so results can be matched to calls.
+type toolCallIDs map[string]bool
+
+// unique returns the ID if it's not empty and not seen before, or else a new unique ID from the generator.
+func (ids toolCallIDs) unique(id string, generate IDGenerator) (string, error) {
+	for range maxIDAttempts {
+		if id != "" && !ids[id] {
+			ids[id] = true
+			return id, nil
+		}
+		id = generate()
+	}
+	return "", fmt.Errorf("no unique tool call ID after %v attempts, last was %q", maxIDAttempts, id)
+}
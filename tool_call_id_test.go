@@ -0,0 +1,32 @@
+package google_test
+
+import (
+	"testing"
+
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestRandomID(t *testing.T) {
+	t.Run("returns unique 128-bit hex IDs", func(t *testing.T) {
+		seen := map[string]bool{}
+		for range 1000 {
+			id := google.RandomID()
+			is.Equal(t, 32, len(id))
+			is.True(t, !seen[id], "duplicate ID "+id)
+			seen[id] = true
+		}
+	})
+}
+
+func TestNewCounterIDGenerator(t *testing.T) {
+	t.Run("returns the prefix and an increasing counter", func(t *testing.T) {
+		g := google.NewCounterIDGenerator("call_")
+		is.Equal(t, "call_1", g())
+		is.Equal(t, "call_2", g())
+
+		g = google.NewCounterIDGenerator("call_")
+		is.Equal(t, "call_1", g())
+	})
+}
@@ -0,0 +1,36 @@
+package google
+
+import (
+	"encoding/json"
+
+	"maragu.dev/gai"
+)
+
+// ToolResultEncoder encodes a [gai.ToolResult] into the response of a Gemini function response.
+type ToolResultEncoder func(result gai.ToolResult) (map[string]any, error)
+
+// EncodeToolResultAsString always sends the tool result content as a string in the "output" key,
+// or the error message in the "error" key.
+func EncodeToolResultAsString(result gai.ToolResult) (map[string]any, error) {
+	if result.Err != nil {
+		return map[string]any{"error": result.Err.Error()}, nil
+	}
+	return map[string]any{"output": result.Content}, nil
+}
+
+// EncodeToolResultAsJSON detects JSON tool result content and sends it structured, so the model doesn't have to
+// parse escaped JSON from a string.
+// JSON values, including objects, are sent in the "output" key, so a tool's own "error" key can't be mistaken for
+// a tool error. Content that isn't JSON, and errors, are encoded like [EncodeToolResultAsString].
+// This is the default encoder.
+func EncodeToolResultAsJSON(result gai.ToolResult) (map[string]any, error) {
+	if result.Err != nil || !json.Valid([]byte(result.Content)) {
+		return EncodeToolResultAsString(result)
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(result.Content), &value); err != nil {
+		return nil, err
+	}
+	return map[string]any{"output": value}, nil
+}
@@ -0,0 +1,63 @@
+package google_test
+
+import (
+	"errors"
+	"testing"
+
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestEncodeToolResultAsString(t *testing.T) {
+	t.Run("wraps content in output", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsString(gai.ToolResult{Content: `{"name":"Dune"}`})
+		is.NotError(t, err)
+		is.Equal[any](t, `{"name":"Dune"}`, res["output"])
+	})
+
+	t.Run("wraps error in error", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsString(gai.ToolResult{Err: errors.New("oh no")})
+		is.NotError(t, err)
+		is.Equal[any](t, "oh no", res["error"])
+	})
+}
+
+func TestEncodeToolResultAsJSON(t *testing.T) {
+	t.Run("sends JSON objects structured in output", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsJSON(gai.ToolResult{Content: `{"name":"Dune","year":1965}`})
+		is.NotError(t, err)
+		is.Equal(t, 1, len(res))
+		output := res["output"].(map[string]any)
+		is.Equal[any](t, "Dune", output["name"])
+		is.Equal[any](t, float64(1965), output["year"])
+	})
+
+	t.Run("does not mistake an error key in JSON content for a tool error", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsJSON(gai.ToolResult{Content: `{"error":"not found"}`})
+		is.NotError(t, err)
+		_, ok := res["error"]
+		is.True(t, !ok, "should not have top-level error key")
+		is.Equal[any](t, "not found", res["output"].(map[string]any)["error"])
+	})
+
+	t.Run("sends other JSON values structured in output", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsJSON(gai.ToolResult{Content: `["logo.jpg","readme.txt"]`})
+		is.NotError(t, err)
+		is.Equal(t, 2, len(res["output"].([]any)))
+	})
+
+	t.Run("wraps non-JSON content in output", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsJSON(gai.ToolResult{Content: "Hi!\n"})
+		is.NotError(t, err)
+		is.Equal[any](t, "Hi!\n", res["output"])
+	})
+
+	t.Run("wraps error in error", func(t *testing.T) {
+		res, err := google.EncodeToolResultAsJSON(gai.ToolResult{Content: `{}`, Err: errors.New("oh no")})
+		is.NotError(t, err)
+		is.Equal[any](t, "oh no", res["error"])
+		is.Equal(t, 1, len(res))
+	})
+}
@@ -0,0 +1,213 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"maragu.dev/gai"
+)
+
+// ErrMaxIterations is returned by [ToolRunner.Run] if the model still calls tools after the maximum number of iterations.
+var ErrMaxIterations = errors.New("max iterations reached")
+
+// ToolRunner runs a chat completion with tools in a loop:
+// it executes the tool calls from the model, sends the results back, and repeats until the model responds
+// without calling any tools.
+type ToolRunner struct {
+	chatCompleter gai.ChatCompleter
+	maxIterations int
+	onText        func(text string)
+	toolTimeout   time.Duration
+}
+
+type NewToolRunnerOptions struct {
+	ChatCompleter gai.ChatCompleter
+
+	// MaxIterations is the maximum number of chat completions in one run. Defaults to 10.
+	MaxIterations int
+
+	// OnText is called with text parts as they are streamed, including text from intermediate turns.
+	OnText func(text string)
+
+	// ToolTimeout for each tool execution. Zero means no timeout.
+	ToolTimeout time.Duration
+}
+
+func NewToolRunner(opts NewToolRunnerOptions) *ToolRunner {
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 10
+	}
+	if opts.OnText == nil {
+		opts.OnText = func(string) {}
+	}
+
+	return &ToolRunner{
+		chatCompleter: opts.ChatCompleter,
+		maxIterations: opts.MaxIterations,
+		onText:        opts.OnText,
+		toolTimeout:   opts.ToolTimeout,
+	}
+}
+
+// ToolRunResult of [ToolRunner.Run].
+type ToolRunResult struct {
+	// Messages is the transcript of all turns, starting with the request messages.
+	Messages []gai.Message
+
+	// Iterations is the number of chat completions made.
+	Iterations int
+
+	// Usage summed over all chat completions.
+	Usage gai.ChatCompleteResponseUsage
+}
+
+// Run the chat completion request, executing tools from [gai.ChatCompleteRequest.Tools] until the model is done.
+// Tool calls from the same model turn are independent, so they are executed concurrently.
+// Tool errors and panics are sent back to the model as part of the tool result, not returned.
+// Tool calls in the last iteration are not executed, since their results can't be sent back to the model.
+// Data parts are sent again in every iteration, so data that can't be rewound with [io.Seeker] is buffered in memory.
+// The result is returned even if there's an error, so the transcript up to that point is available.
+func (r *ToolRunner) Run(ctx context.Context, req gai.ChatCompleteRequest) (ToolRunResult, error) {
+	messages, err := bufferData(req.Messages)
+	if err != nil {
+		return ToolRunResult{}, err
+	}
+	result := ToolRunResult{
+		Messages: messages,
+	}
+
+	for {
+		req.Messages = result.Messages
+
+		res, err := r.chatCompleter.ChatComplete(ctx, req)
+		if err != nil {
+			return result, fmt.Errorf("error chat-completing: %w", err)
+		}
+		result.Iterations++
+
+		var parts []gai.MessagePart
+		var toolCalls []gai.ToolCall
+		for part, err := range res.Parts() {
+			if err != nil {
+				return result, fmt.Errorf("error streaming response: %w", err)
+			}
+
+			parts = append(parts, part)
+
+			switch part.Type {
+			case gai.MessagePartTypeText:
+				r.onText(part.Text())
+			case gai.MessagePartTypeToolCall:
+				toolCalls = append(toolCalls, part.ToolCall())
+			}
+		}
+
+		if res.Meta != nil {
+			result.Usage.PromptTokens += res.Meta.Usage.PromptTokens
+			result.Usage.ThoughtsTokens += res.Meta.Usage.ThoughtsTokens
+			result.Usage.CompletionTokens += res.Meta.Usage.CompletionTokens
+		}
+
+		result.Messages = append(result.Messages, gai.Message{Role: gai.MessageRoleModel, Parts: parts})
+
+		if len(toolCalls) == 0 {
+			return result, nil
+		}
+
+		// Don't execute tool calls whose results could never be sent back to the model
+		if result.Iterations >= r.maxIterations {
+			break
+		}
+
+		toolResults := r.executeToolCalls(ctx, req.Tools, toolCalls)
+
+		message := gai.Message{Role: gai.MessageRoleUser}
+		for _, toolResult := range toolResults {
+			message.Parts = append(message.Parts, gai.NewUserToolResultMessage(toolResult).Parts...)
+		}
+		result.Messages = append(result.Messages, message)
+	}
+
+	return result, ErrMaxIterations
+}
+
+// bufferData of data parts in copies of the messages, if the data can't be rewound.
+func bufferData(messages []gai.Message) ([]gai.Message, error) {
+	buffered := make([]gai.Message, len(messages))
+	for i, m := range messages {
+		m.Parts = append([]gai.MessagePart{}, m.Parts...)
+		for j, part := range m.Parts {
+			if part.Type != gai.MessagePartTypeData || part.Data == nil {
+				continue
+			}
+			if _, ok := part.Data.(io.Seeker); ok {
+				continue
+			}
+			data, err := io.ReadAll(part.Data)
+			if err != nil {
+				return nil, fmt.Errorf("error reading request data: %w", err)
+			}
+			m.Parts[j].Data = bytes.NewReader(data)
+		}
+		buffered[i] = m
+	}
+	return buffered, nil
+}
+
+// executeToolCalls concurrently, returning the results in the same order as the calls.
+func (r *ToolRunner) executeToolCalls(ctx context.Context, tools []gai.Tool, toolCalls []gai.ToolCall) []gai.ToolResult {
+	results := make([]gai.ToolResult, len(toolCalls))
+
+	var wg sync.WaitGroup
+	for i, toolCall := range toolCalls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.executeToolCall(ctx, tools, toolCall)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// executeToolCall, recovering a panic in the tool into an error in the result.
+func (r *ToolRunner) executeToolCall(ctx context.Context, tools []gai.Tool, toolCall gai.ToolCall) (result gai.ToolResult) {
+	result = gai.ToolResult{
+		ID:   toolCall.ID,
+		Name: toolCall.Name,
+	}
+
+	defer func() {
+		if rec := recover(); rec != nil {
+			result.Content = ""
+			result.Err = fmt.Errorf("tool %v panicked: %v", toolCall.Name, rec)
+		}
+	}()
+
+	var tool *gai.Tool
+	for i := range tools {
+		if tools[i].Name == toolCall.Name {
+			tool = &tools[i]
+			break
+		}
+	}
+	if tool == nil {
+		result.Err = fmt.Errorf("unknown tool %v", toolCall.Name)
+		return result
+	}
+
+	if r.toolTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.toolTimeout)
+		defer cancel()
+	}
+
+	result.Content, result.Err = tool.Execute(ctx, toolCall.Args)
+	return result
+}
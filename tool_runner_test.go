@@ -0,0 +1,306 @@
+package google_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"maragu.dev/gai"
+	"maragu.dev/gai/tools"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestToolRunner_Run(t *testing.T) {
+	t.Run("can run tools with a model until it's done", func(t *testing.T) {
+		root, err := os.OpenRoot("testdata")
+		is.NotError(t, err)
+
+		var text string
+		r := google.NewToolRunner(google.NewToolRunnerOptions{
+			ChatCompleter: newChatCompleter(t),
+			OnText:        func(s string) { text += s },
+		})
+
+		res, err := r.Run(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				gai.NewUserTextMessage("What is in the readme.txt file?"),
+			},
+			Temperature: gai.Ptr(gai.Temperature(0)),
+			Tools: []gai.Tool{
+				tools.NewReadFile(root),
+			},
+		})
+		is.NotError(t, err)
+
+		t.Log(text)
+		is.Equal(t, 2, res.Iterations)
+		is.Equal(t, 4, len(res.Messages))
+		is.True(t, strings.Contains(text, "Hi"), text)
+		is.True(t, res.Usage.PromptTokens > 0, "should have prompt tokens")
+	})
+
+	t.Run("executes tool calls, sends results back, and aggregates usage", func(t *testing.T) {
+		cc := &fakeChatCompleter{
+			responses: [][]gai.MessagePart{
+				{gai.TextMessagePart("Let me check. "), gai.ToolCallPart("1", "echo", json.RawMessage(`{"s":"a"}`))},
+				{gai.TextMessagePart("The answer is a.")},
+			},
+		}
+
+		var text string
+		r := google.NewToolRunner(google.NewToolRunnerOptions{
+			ChatCompleter: cc,
+			OnText:        func(s string) { text += s },
+		})
+
+		res, err := r.Run(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Echo a.")},
+			Tools:    []gai.Tool{newEchoTool(nil)},
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, "Let me check. The answer is a.", text)
+		is.Equal(t, 2, res.Iterations)
+		is.Equal(t, 4, len(res.Messages))
+		is.Equal(t, gai.MessageRoleModel, res.Messages[1].Role)
+		is.Equal(t, gai.MessageRoleUser, res.Messages[2].Role)
+
+		toolResult := res.Messages[2].Parts[0].ToolResult()
+		is.Equal(t, "1", toolResult.ID)
+		is.Equal(t, "echo", toolResult.Name)
+		is.Equal(t, "a", toolResult.Content)
+		is.NotError(t, toolResult.Err)
+
+		is.Equal(t, 2, len(cc.requests))
+		is.Equal(t, 3, len(cc.requests[1].Messages))
+		is.Equal(t, 20, res.Usage.PromptTokens)
+		is.Equal(t, 10, res.Usage.CompletionTokens)
+	})
+
+	t.Run("executes tool calls from the same turn concurrently and in order", func(t *testing.T) {
+		cc := &fakeChatCompleter{
+			responses: [][]gai.MessagePart{
+				{
+					gai.ToolCallPart("1", "echo", json.RawMessage(`{"s":"a"}`)),
+					gai.ToolCallPart("2", "echo", json.RawMessage(`{"s":"b"}`)),
+				},
+				{gai.TextMessagePart("Done.")},
+			},
+		}
+
+		// Both calls must be running at the same time to get past the barrier
+		var barrier sync.WaitGroup
+		barrier.Add(2)
+
+		r := google.NewToolRunner(google.NewToolRunnerOptions{
+			ChatCompleter: cc,
+			ToolTimeout:   time.Second,
+		})
+
+		res, err := r.Run(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Echo a and b.")},
+			Tools:    []gai.Tool{newEchoTool(&barrier)},
+		})
+		is.NotError(t, err)
+
+		parts := res.Messages[2].Parts
+		is.Equal(t, 2, len(parts))
+		is.Equal(t, "a", parts[0].ToolResult().Content)
+		is.NotError(t, parts[0].ToolResult().Err)
+		is.Equal(t, "b", parts[1].ToolResult().Content)
+		is.NotError(t, parts[1].ToolResult().Err)
+	})
+
+	t.Run("sends tool errors, timeouts, panics, and unknown tools back to the model", func(t *testing.T) {
+		cc := &fakeChatCompleter{
+			responses: [][]gai.MessagePart{
+				{
+					gai.ToolCallPart("1", "fail", json.RawMessage(`{}`)),
+					gai.ToolCallPart("2", "sleep", json.RawMessage(`{}`)),
+					gai.ToolCallPart("3", "nope", json.RawMessage(`{}`)),
+					gai.ToolCallPart("4", "panic", json.RawMessage(`{}`)),
+				},
+				{gai.TextMessagePart("Oh no.")},
+			},
+		}
+
+		r := google.NewToolRunner(google.NewToolRunnerOptions{
+			ChatCompleter: cc,
+			ToolTimeout:   time.Millisecond,
+		})
+
+		res, err := r.Run(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Try it.")},
+			Tools: []gai.Tool{
+				{
+					Name: "fail",
+					Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+						return "", errors.New("oh no")
+					},
+				},
+				{
+					Name: "sleep",
+					Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+						<-ctx.Done()
+						return "", ctx.Err()
+					},
+				},
+				{
+					Name: "panic",
+					Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+						panic("oh no")
+					},
+				},
+			},
+		})
+		is.NotError(t, err)
+
+		parts := res.Messages[2].Parts
+		is.Equal(t, "oh no", parts[0].ToolResult().Err.Error())
+		is.True(t, errors.Is(parts[1].ToolResult().Err, context.DeadlineExceeded), "should time out")
+		is.Equal(t, "unknown tool nope", parts[2].ToolResult().Err.Error())
+		is.Equal(t, "tool panic panicked: oh no", parts[3].ToolResult().Err.Error())
+	})
+
+	t.Run("returns an error after max iterations without executing the last tool calls", func(t *testing.T) {
+		cc := &fakeChatCompleter{
+			responses: [][]gai.MessagePart{
+				{gai.ToolCallPart("1", "echo", json.RawMessage(`{"s":"a"}`))},
+				{gai.ToolCallPart("2", "echo", json.RawMessage(`{"s":"a"}`))},
+				{gai.TextMessagePart("Done.")},
+			},
+		}
+
+		r := google.NewToolRunner(google.NewToolRunnerOptions{
+			ChatCompleter: cc,
+			MaxIterations: 2,
+		})
+
+		var calls int
+		echo := newEchoTool(nil)
+		execute := echo.Execute
+		echo.Execute = func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			calls++
+			return execute(ctx, rawArgs)
+		}
+
+		res, err := r.Run(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("Echo a forever.")},
+			Tools:    []gai.Tool{echo},
+		})
+		is.True(t, errors.Is(err, google.ErrMaxIterations), "should be max iterations error")
+		is.Equal(t, 2, res.Iterations)
+		is.Equal(t, 1, calls)
+		is.Equal(t, 4, len(res.Messages))
+		is.Equal(t, gai.MessageRoleModel, res.Messages[3].Role)
+	})
+}
+
+func TestToolRunner_Run_Data(t *testing.T) {
+	t.Run("sends data parts in every iteration", func(t *testing.T) {
+		baseURL, requests := newRecordingChatServer(t, http.StatusOK, toolCallStream)
+		c := google.NewClient(google.NewClientOptions{BaseURL: baseURL, Key: "test"})
+
+		r := google.NewToolRunner(google.NewToolRunnerOptions{
+			ChatCompleter: c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash}),
+			MaxIterations: 2,
+		})
+
+		_, err := r.Run(t.Context(), gai.ChatCompleteRequest{
+			Messages: []gai.Message{
+				{Role: gai.MessageRoleUser, Parts: []gai.MessagePart{
+					gai.TextMessagePart("What's the weather where these were taken?"),
+					gai.DataMessagePart("image/png", bytes.NewReader([]byte("seekable"))),
+					gai.DataMessagePart("image/png", io.MultiReader(strings.NewReader("not seekable"))),
+				}},
+			},
+			Tools: []gai.Tool{{
+				Name: "get_weather",
+				Execute: func(ctx context.Context, args json.RawMessage) (string, error) {
+					return "Sunny", nil
+				},
+			}},
+		})
+		is.True(t, errors.Is(err, google.ErrMaxIterations), "should reach max iterations")
+
+		bodies := requests()
+		is.Equal(t, 2, len(bodies))
+		for _, body := range bodies {
+			var req struct {
+				Contents []struct {
+					Parts []struct {
+						InlineData *struct {
+							Data []byte
+						}
+					}
+				}
+			}
+			is.NotError(t, json.Unmarshal(body, &req))
+			is.Equal(t, "seekable", string(req.Contents[0].Parts[1].InlineData.Data))
+			is.Equal(t, "not seekable", string(req.Contents[0].Parts[2].InlineData.Data))
+		}
+	})
+}
+
+// fakeChatCompleter returns the responses in order, recording the requests.
+type fakeChatCompleter struct {
+	requests  []gai.ChatCompleteRequest
+	responses [][]gai.MessagePart
+}
+
+func (f *fakeChatCompleter) ChatComplete(ctx context.Context, req gai.ChatCompleteRequest) (gai.ChatCompleteResponse, error) {
+	f.requests = append(f.requests, req)
+
+	parts := f.responses[0]
+	f.responses = f.responses[1:]
+
+	res := gai.NewChatCompleteResponse(func(yield func(gai.MessagePart, error) bool) {
+		for _, part := range parts {
+			if !yield(part, nil) {
+				return
+			}
+		}
+	})
+	res.Meta = &gai.ChatCompleteResponseMetadata{
+		Usage: gai.ChatCompleteResponseUsage{PromptTokens: 10, CompletionTokens: 5},
+	}
+	return res, nil
+}
+
+// newEchoTool returns its "s" argument. If barrier is not nil, it waits for all other calls to reach it first.
+func newEchoTool(barrier *sync.WaitGroup) gai.Tool {
+	return gai.Tool{
+		Name: "echo",
+		Execute: func(ctx context.Context, rawArgs json.RawMessage) (string, error) {
+			if barrier != nil {
+				barrier.Done()
+				done := make(chan struct{})
+				go func() {
+					barrier.Wait()
+					close(done)
+				}()
+				select {
+				case <-done:
+				case <-ctx.Done():
+					return "", ctx.Err()
+				}
+			}
+
+			var args struct{ S string }
+			if err := json.Unmarshal(rawArgs, &args); err != nil {
+				return "", err
+			}
+			return args.S, nil
+		},
+	}
+}
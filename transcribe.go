@@ -0,0 +1,305 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+// Transcriber transcribes audio to text with Gemini models, with speaker labels and timestamps.
+type Transcriber struct {
+	Client      *genai.Client
+	inlineLimit int
+	log         *slog.Logger
+	model       ChatCompleteModel
+	tracer      trace.Tracer
+}
+
+type NewTranscriberOptions struct {
+	// InlineLimit is the maximum audio size in bytes sent inline with the request.
+	// Larger audio is uploaded with the Files API first, which is only available on the Gemini API.
+	// Defaults to 15 MB, to stay below the 20 MB request size limit.
+	InlineLimit int
+
+	// Model defaults to the default model of [ModelFamilyChat], see [ModelSpecs].
+	Model ChatCompleteModel
+}
+
+func (c *Client) NewTranscriber(opts NewTranscriberOptions) *Transcriber {
+	if opts.InlineLimit <= 0 {
+		opts.InlineLimit = 15 * 1024 * 1024
+	}
+	if opts.Model == "" {
+		opts.Model = defaultModel[ChatCompleteModel](ModelFamilyChat)
+	}
+
+	return &Transcriber{
+		Client:      c.Client,
+		inlineLimit: opts.InlineLimit,
+		log:         c.log,
+		model:       opts.Model,
+		tracer:      c.tracer,
+	}
+}
+
+// TranscribeRequest for [Transcriber.Transcribe].
+type TranscribeRequest struct {
+	Audio io.Reader
+
+	// Context about the audio to improve the transcript, like the topic or names and terms used. Optional.
+	Context string
+
+	// LanguageCode of the speech, for example "en-US". Detected if not set.
+	LanguageCode string
+
+	MIMEType string
+
+	// Speakers is the expected number of speakers. Detected if not set.
+	Speakers int
+}
+
+// TranscribeResponse from [Transcriber.Transcribe].
+type TranscribeResponse struct {
+	// LanguageCode of the speech, as given in the request or detected.
+	LanguageCode string
+
+	Segments []TranscriptSegment
+}
+
+// Text of the whole transcript, with one segment per line.
+func (r TranscribeResponse) Text() string {
+	var lines []string
+	for _, s := range r.Segments {
+		lines = append(lines, s.Text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// TranscriptSegment is a continuous part of the speech by a single speaker.
+type TranscriptSegment struct {
+	// Speaker label, like "Speaker 1", or the speaker's name if it's clear from the audio.
+	Speaker string
+
+	// Start and End of the segment, from the beginning of the audio.
+	Start time.Duration
+	End   time.Duration
+
+	Text string
+}
+
+// transcriptSchema for the model response. Timestamps are strings, because models are better at writing those than seconds.
+var transcriptSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"languageCode": {Type: genai.TypeString, Description: "BCP-47 language code of the speech, like en-US."},
+		"segments": {
+			Type: genai.TypeArray,
+			Items: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"speaker": {Type: genai.TypeString, Description: `Speaker label, like "Speaker 1", or the speaker's name if it's clear from the audio.`},
+					"start":   {Type: genai.TypeString, Description: "Start timestamp in the format MM:SS."},
+					"end":     {Type: genai.TypeString, Description: "End timestamp in the format MM:SS."},
+					"text":    {Type: genai.TypeString, Description: "Verbatim transcript of the segment."},
+				},
+				PropertyOrdering: []string{"speaker", "start", "end", "text"},
+				Required:         []string{"speaker", "start", "end", "text"},
+			},
+		},
+	},
+	PropertyOrdering: []string{"languageCode", "segments"},
+	Required:         []string{"languageCode", "segments"},
+}
+
+type transcript struct {
+	LanguageCode string `json:"languageCode"`
+	Segments     []struct {
+		Speaker string `json:"speaker"`
+		Start   string `json:"start"`
+		End     string `json:"end"`
+		Text    string `json:"text"`
+	} `json:"segments"`
+}
+
+// Transcribe the request audio.
+func (t *Transcriber) Transcribe(ctx context.Context, req TranscribeRequest) (TranscribeResponse, error) {
+	ctx, span := t.tracer.Start(ctx, "google.transcribe",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(t.model)),
+			attribute.String("ai.mime_type", req.MIMEType),
+		),
+	)
+	defer span.End()
+
+	data, err := io.ReadAll(req.Audio)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "audio read failed")
+		return TranscribeResponse{}, fmt.Errorf("error reading audio: %w", err)
+	}
+	span.SetAttributes(attribute.Int("ai.audio_bytes", len(data)))
+
+	audio := genai.NewPartFromBytes(data, req.MIMEType)
+	if len(data) > t.inlineLimit && !isVertexAI(t.Client) {
+		file, err := t.upload(ctx, data, req.MIMEType)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "audio upload failed")
+			return TranscribeResponse{}, fmt.Errorf("error uploading audio: %w", err)
+		}
+		defer func() {
+			// Use a new context, so the file is deleted even if ctx is cancelled
+			if _, err := t.Client.Files.Delete(trackRetries(context.WithoutCancel(ctx)), file.Name, nil); err != nil {
+				logWithTrace(ctx, t.log).Info("Error deleting uploaded audio", "name", file.Name, "error", err)
+			}
+		}()
+		audio = genai.NewPartFromURI(file.URI, file.MIMEType)
+		span.SetAttributes(attribute.Bool("ai.uploaded", true))
+	}
+
+	prompt := "Transcribe the speech in the audio verbatim. " +
+		"Split the transcript into segments whenever the speaker changes or there is a pause, " +
+		"with a start and end timestamp for each segment."
+	if req.Speakers > 0 {
+		prompt += fmt.Sprintf(" There are %v speakers.", req.Speakers)
+		span.SetAttributes(attribute.Int("ai.speaker_count", req.Speakers))
+	}
+	if req.LanguageCode != "" {
+		prompt += fmt.Sprintf(" The speech is in the language with code %v.", req.LanguageCode)
+		span.SetAttributes(attribute.String("ai.language_code", req.LanguageCode))
+	}
+	if req.Context != "" {
+		prompt += "\n\nContext about the audio:\n" + req.Context
+	}
+
+	config := genai.GenerateContentConfig{
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   transcriptSchema,
+		Temperature:      gai.Ptr(float32(0)),
+	}
+
+	contents := []*genai.Content{
+		genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText(prompt), audio}, genai.RoleUser),
+	}
+
+	res, err := t.Client.Models.GenerateContent(trackRetries(ctx), modelName(t.Client, t.model), contents, &config)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "transcription failed")
+		return TranscribeResponse{}, err
+	}
+
+	var tr transcript
+	if err := json.Unmarshal([]byte(res.Text()), &tr); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "transcript parse failed")
+		return TranscribeResponse{}, fmt.Errorf("error parsing transcript: %w", err)
+	}
+
+	result := TranscribeResponse{LanguageCode: tr.LanguageCode}
+	if req.LanguageCode != "" {
+		result.LanguageCode = req.LanguageCode
+	}
+	for _, s := range tr.Segments {
+		start, err := parseTimestamp(s.Start)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "transcript parse failed")
+			return TranscribeResponse{}, fmt.Errorf("error parsing transcript: %w", err)
+		}
+		end, err := parseTimestamp(s.End)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "transcript parse failed")
+			return TranscribeResponse{}, fmt.Errorf("error parsing transcript: %w", err)
+		}
+		result.Segments = append(result.Segments, TranscriptSegment{
+			Speaker: s.Speaker,
+			Start:   start,
+			End:     end,
+			Text:    s.Text,
+		})
+	}
+
+	if res.UsageMetadata != nil {
+		span.SetAttributes(
+			attribute.Int("ai.prompt_tokens", int(res.UsageMetadata.PromptTokenCount)),
+			attribute.Int("ai.completion_tokens", int(res.UsageMetadata.CandidatesTokenCount)),
+		)
+	}
+	span.SetAttributes(attribute.Int("ai.segment_count", len(result.Segments)))
+
+	return result, nil
+}
+
+// upload the audio with the Files API and wait for it to be processed.
+func (t *Transcriber) upload(ctx context.Context, data []byte, mimeType string) (*genai.File, error) {
+	file, err := t.Client.Files.Upload(trackRetries(ctx), bytes.NewReader(data), &genai.UploadFileConfig{MIMEType: mimeType})
+	if err != nil {
+		return nil, err
+	}
+
+	log := logWithTrace(ctx, t.log).With("name", file.Name)
+	log.Debug("Uploaded audio", "state", file.State)
+
+	for file.State == genai.FileStateProcessing {
+		log.Debug("Uploaded audio is processing, checking again")
+
+		timer := time.NewTimer(time.Second)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		file, err = t.Client.Files.Get(trackRetries(ctx), file.Name, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error getting file: %w", err)
+		}
+	}
+
+	if file.State == genai.FileStateFailed {
+		return nil, fmt.Errorf("file processing failed")
+	}
+
+	return file, nil
+}
+
+// parseTimestamp like "MM:SS", "HH:MM:SS", or with fractional seconds like "MM:SS.mmm".
+func parseTimestamp(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || seconds < 0 || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	d := time.Duration(seconds * float64(time.Second))
+
+	for i, unit := range []time.Duration{time.Minute, time.Hour}[:len(parts)-1] {
+		n, err := strconv.Atoi(parts[len(parts)-2-i])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
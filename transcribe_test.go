@@ -0,0 +1,86 @@
+package google_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestTranscriber_Transcribe(t *testing.T) {
+	t.Run("can transcribe audio with timestamps", func(t *testing.T) {
+		c := newClient(t)
+		tr := c.NewTranscriber(google.NewTranscriberOptions{
+			Model: google.ChatCompleteModelGemini2_5Flash,
+		})
+
+		res, err := tr.Transcribe(t.Context(), google.TranscribeRequest{
+			Audio:    bytes.NewReader(audio),
+			MIMEType: "audio/mp4",
+		})
+		is.NotError(t, err)
+
+		is.True(t, len(res.Segments) > 0, "should have segments")
+		is.True(t, strings.Contains(strings.ToLower(res.Text()), "hello there"), "should contain hello there")
+		is.True(t, strings.HasPrefix(res.LanguageCode, "en"), "should detect English")
+		for _, s := range res.Segments {
+			is.True(t, s.Speaker != "", "should have a speaker")
+			is.True(t, s.End >= s.Start, "should end after start")
+		}
+	})
+
+	t.Run("can transcribe audio uploaded with the Files API", func(t *testing.T) {
+		c := newClient(t)
+		tr := c.NewTranscriber(google.NewTranscriberOptions{
+			InlineLimit: 1,
+			Model:       google.ChatCompleteModelGemini2_5Flash,
+		})
+
+		res, err := tr.Transcribe(t.Context(), google.TranscribeRequest{
+			Audio:        bytes.NewReader(audio),
+			LanguageCode: "en-US",
+			MIMEType:     "audio/mp4",
+			Speakers:     1,
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, "en-US", res.LanguageCode)
+		is.True(t, strings.Contains(strings.ToLower(res.Text()), "hello there"), "should contain hello there")
+	})
+}
+
+func TestParseTimestamp(t *testing.T) {
+	t.Run("parses minutes, hours, and fractional seconds", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			input    string
+			expected time.Duration
+		}{
+			{"MM:SS", "01:02", time.Minute + 2*time.Second},
+			{"MM:SS over an hour", "75:00", 75 * time.Minute},
+			{"HH:MM:SS", "01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+			{"fractional seconds", "00:01.250", 1250 * time.Millisecond},
+			{"fractional seconds with hours", "1:00:00.5", time.Hour + 500*time.Millisecond},
+			{"surrounding whitespace", " 00:05 ", 5 * time.Second},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				d, err := google.ParseTimestamp(test.input)
+				is.NotError(t, err)
+				is.Equal(t, test.expected, d)
+			})
+		}
+	})
+
+	t.Run("errors on negative or malformed timestamps", func(t *testing.T) {
+		for _, input := range []string{"", "5", "1:2:3:4", "-1:00", "00:-5", "-1:00:00", "a:00", "00:b", "00:", ":00", "00:NaN", "00:Inf", "1.5:00"} {
+			_, err := google.ParseTimestamp(input)
+			is.True(t, err != nil, "should error on "+input)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package google
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/genai"
+)
+
+// UsageDetails is the token usage of a response, broken down by modality.
+type UsageDetails struct {
+	// PromptTokens in the request, including cached tokens.
+	PromptTokens ModalityTokens
+
+	// CachedTokens of the prompt, read from the context cache.
+	CachedTokens ModalityTokens
+
+	// ToolUsePromptTokens from built-in tools like code execution and URL context, in addition to the prompt tokens.
+	ToolUsePromptTokens ModalityTokens
+
+	// CompletionTokens in the response, excluding thoughts.
+	CompletionTokens ModalityTokens
+
+	ThoughtsTokens int
+
+	TotalTokens int
+}
+
+// ModalityTokens is a token count, broken down by modality.
+// The modality counts don't always add up to the total, because the API doesn't always return them.
+type ModalityTokens struct {
+	Total int
+
+	Audio    int
+	Document int
+	Image    int
+	Text     int
+	Video    int
+}
+
+func convertUsage(usage *genai.GenerateContentResponseUsageMetadata) UsageDetails {
+	return UsageDetails{
+		PromptTokens:        convertModalityTokens(usage.PromptTokenCount, usage.PromptTokensDetails),
+		CachedTokens:        convertModalityTokens(usage.CachedContentTokenCount, usage.CacheTokensDetails),
+		ToolUsePromptTokens: convertModalityTokens(usage.ToolUsePromptTokenCount, usage.ToolUsePromptTokensDetails),
+		CompletionTokens:    convertModalityTokens(usage.CandidatesTokenCount, usage.CandidatesTokensDetails),
+		ThoughtsTokens:      int(usage.ThoughtsTokenCount),
+		TotalTokens:         int(usage.TotalTokenCount),
+	}
+}
+
+func convertModalityTokens(total int32, details []*genai.ModalityTokenCount) ModalityTokens {
+	t := ModalityTokens{Total: int(total)}
+	for _, d := range details {
+		switch d.Modality {
+		case genai.MediaModalityAudio:
+			t.Audio += int(d.TokenCount)
+		case genai.MediaModalityDocument:
+			t.Document += int(d.TokenCount)
+		case genai.MediaModalityImage:
+			t.Image += int(d.TokenCount)
+		case genai.MediaModalityText:
+			t.Text += int(d.TokenCount)
+		case genai.MediaModalityVideo:
+			t.Video += int(d.TokenCount)
+		}
+	}
+	return t
+}
+
+// usageAttributes for the span, with modality counts only if they're not zero.
+func usageAttributes(u UsageDetails) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Int("ai.cached_tokens", u.CachedTokens.Total),
+		attribute.Int("ai.tool_use_prompt_tokens", u.ToolUsePromptTokens.Total),
+		attribute.Int("ai.total_tokens", u.TotalTokens),
+	}
+	attrs = append(attrs, modalityAttributes("ai.prompt_tokens", u.PromptTokens)...)
+	attrs = append(attrs, modalityAttributes("ai.cached_tokens", u.CachedTokens)...)
+	attrs = append(attrs, modalityAttributes("ai.tool_use_prompt_tokens", u.ToolUsePromptTokens)...)
+	attrs = append(attrs, modalityAttributes("ai.completion_tokens", u.CompletionTokens)...)
+	return attrs
+}
+
+// modalityAttributes like "ai.prompt_tokens.audio".
+func modalityAttributes(prefix string, t ModalityTokens) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, m := range []struct {
+		name  string
+		count int
+	}{
+		{"audio", t.Audio},
+		{"document", t.Document},
+		{"image", t.Image},
+		{"text", t.Text},
+		{"video", t.Video},
+	} {
+		if m.count > 0 {
+			attrs = append(attrs, attribute.Int(prefix+"."+m.name, m.count))
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,68 @@
+package google_test
+
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestConvertUsage(t *testing.T) {
+	t.Run("breaks down token counts by modality", func(t *testing.T) {
+		usage := google.ConvertUsage(&genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount: 300,
+			PromptTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 10},
+				{Modality: genai.MediaModalityImage, TokenCount: 258},
+				{Modality: genai.MediaModalityAudio, TokenCount: 32},
+			},
+			CachedContentTokenCount: 32,
+			CacheTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityAudio, TokenCount: 32},
+			},
+			ToolUsePromptTokenCount: 5,
+			CandidatesTokenCount:    1300,
+			CandidatesTokensDetails: []*genai.ModalityTokenCount{
+				{Modality: genai.MediaModalityText, TokenCount: 10},
+				{Modality: genai.MediaModalityImage, TokenCount: 1290},
+			},
+			ThoughtsTokenCount: 7,
+			TotalTokenCount:    1612,
+		})
+
+		is.Equal(t, google.ModalityTokens{Total: 300, Text: 10, Image: 258, Audio: 32}, usage.PromptTokens)
+		is.Equal(t, google.ModalityTokens{Total: 32, Audio: 32}, usage.CachedTokens)
+		is.Equal(t, google.ModalityTokens{Total: 5}, usage.ToolUsePromptTokens)
+		is.Equal(t, google.ModalityTokens{Total: 1300, Text: 10, Image: 1290}, usage.CompletionTokens)
+		is.Equal(t, 7, usage.ThoughtsTokens)
+		is.Equal(t, 1612, usage.TotalTokens)
+	})
+
+	t.Run("records token counts by modality on the span", func(t *testing.T) {
+		recorder, tp := newSpanRecorder(t)
+
+		c := google.NewClient(google.NewClientOptions{
+			BaseURL: newChatServer(t, http.StatusOK, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"A cat."}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":268,"promptTokensDetails":[{"modality":"TEXT","tokenCount":10},{"modality":"IMAGE","tokenCount":258}],"candidatesTokenCount":3,"totalTokenCount":271}}
+
+`),
+			Key:            "test",
+			TracerProvider: tp,
+		})
+		cc := c.NewChatCompleter(google.NewChatCompleterOptions{Model: google.ChatCompleteModelGemini2_5Flash})
+
+		completeAll(t, cc, gai.ChatCompleteRequest{
+			Messages: []gai.Message{gai.NewUserTextMessage("What's in the image?")},
+		})
+
+		spans := recorder.Ended()
+		is.Equal(t, 1, len(spans))
+		attrs := attributeMap(spans[0].Attributes())
+		is.Equal(t, int64(258), attrs["ai.prompt_tokens.image"].AsInt64())
+		_, ok := attrs["ai.prompt_tokens.video"]
+		is.True(t, !ok, "should not have zero video prompt tokens attribute")
+	})
+}
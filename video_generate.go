@@ -0,0 +1,283 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genai"
+	"maragu.dev/gai"
+)
+
+type VideoGenerateModel string
+
+const (
+	VideoGenerateModelVeo3     = VideoGenerateModel("models/veo-3.0-generate-001")
+	VideoGenerateModelVeo3Fast = VideoGenerateModel("models/veo-3.0-fast-generate-001")
+)
+
+// VideoGenerator generates videos from text and image prompts with Veo models.
+// Video generation is a long-running operation, see [VideoGenerator.StartVideoGeneration].
+type VideoGenerator struct {
+	Client          *genai.Client
+	log             *slog.Logger
+	maxPollInterval time.Duration
+	model           VideoGenerateModel
+	pollInterval    time.Duration
+	tracer          trace.Tracer
+}
+
+type NewVideoGeneratorOptions struct {
+	// MaxPollInterval is the maximum time between polls of the operation. Defaults to 1 minute.
+	MaxPollInterval time.Duration
+
+	// Model defaults to the default model of [ModelFamilyVideo], see [ModelSpecs].
+	Model VideoGenerateModel
+
+	// PollInterval is the initial time between polls of the operation, which doubles after each poll
+	// up to MaxPollInterval. Defaults to 10 seconds.
+	PollInterval time.Duration
+}
+
+func (c *Client) NewVideoGenerator(opts NewVideoGeneratorOptions) *VideoGenerator {
+	if opts.Model == "" {
+		opts.Model = defaultModel[VideoGenerateModel](ModelFamilyVideo)
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 10 * time.Second
+	}
+	if opts.MaxPollInterval <= 0 {
+		opts.MaxPollInterval = time.Minute
+	}
+
+	return &VideoGenerator{
+		Client:          c.Client,
+		log:             c.log,
+		maxPollInterval: opts.MaxPollInterval,
+		model:           opts.Model,
+		pollInterval:    opts.PollInterval,
+		tracer:          c.tracer,
+	}
+}
+
+// GenerateVideoRequest for [VideoGenerator.GenerateVideo].
+type GenerateVideoRequest struct {
+	// AspectRatio of the video, "16:9" or "9:16". Defaults to "16:9".
+	AspectRatio string
+
+	// Count is the number of videos to generate. Defaults to 1.
+	Count int
+
+	// DurationSeconds of the video. Defaults to the model default.
+	DurationSeconds int
+
+	// Image to use as the first frame of the video. Optional.
+	Image *InputImage
+
+	// NegativePrompt describes what to discourage in the video.
+	NegativePrompt string
+
+	// PersonGeneration controls whether people can be generated, for example "allow_adult" or "dont_allow".
+	PersonGeneration string
+
+	Prompt string
+}
+
+// GenerateVideoResponse from [VideoGenerator.GenerateVideo] and [VideoGenerator.WaitForVideo].
+type GenerateVideoResponse struct {
+	Videos []GeneratedVideo
+
+	// RAIFilteredReasons are the reasons videos were filtered out by responsible AI (RAI) filters, if any.
+	RAIFilteredReasons []string
+}
+
+// GeneratedVideo data, downloaded from the URI if needed.
+type GeneratedVideo struct {
+	Data     []byte
+	MIMEType string
+	URI      string
+}
+
+// VideoOperation is a handle to an in-flight video generation.
+// Store the Name to continue waiting on the operation from another process with [VideoGenerator.WaitForVideo].
+type VideoOperation struct {
+	Name string
+}
+
+// GenerateVideo from the request, waiting for the generation to finish.
+// See [VideoGenerator.StartVideoGeneration] and [VideoGenerator.WaitForVideo] to wait separately.
+func (g *VideoGenerator) GenerateVideo(ctx context.Context, req GenerateVideoRequest) (GenerateVideoResponse, error) {
+	ctx, span := g.tracer.Start(ctx, "google.generate_video",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(g.model)),
+		),
+	)
+	defer span.End()
+
+	op, err := g.StartVideoGeneration(ctx, req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "video generation start failed")
+		return GenerateVideoResponse{}, err
+	}
+
+	res, err := g.WaitForVideo(ctx, op)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "video generation wait failed")
+		return GenerateVideoResponse{}, err
+	}
+
+	return res, nil
+}
+
+// StartVideoGeneration from the request, returning a handle to the long-running operation.
+func (g *VideoGenerator) StartVideoGeneration(ctx context.Context, req GenerateVideoRequest) (VideoOperation, error) {
+	ctx, span := g.tracer.Start(ctx, "google.start_video_generation",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.model", string(g.model)),
+		),
+	)
+	defer span.End()
+
+	config := genai.GenerateVideosConfig{
+		AspectRatio:      req.AspectRatio,
+		NegativePrompt:   req.NegativePrompt,
+		NumberOfVideos:   int32(req.Count),
+		PersonGeneration: req.PersonGeneration,
+	}
+	if req.AspectRatio != "" {
+		span.SetAttributes(attribute.String("ai.aspect_ratio", req.AspectRatio))
+	}
+	if req.Count > 0 {
+		span.SetAttributes(attribute.Int("ai.video_count", req.Count))
+	}
+	if req.DurationSeconds > 0 {
+		config.DurationSeconds = gai.Ptr(int32(req.DurationSeconds))
+		span.SetAttributes(attribute.Int("ai.duration_seconds", req.DurationSeconds))
+	}
+
+	var image *genai.Image
+	if req.Image != nil {
+		var err error
+		image, err = readInputImage(*req.Image)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "image read failed")
+			return VideoOperation{}, fmt.Errorf("error reading image: %w", err)
+		}
+		span.SetAttributes(attribute.Bool("ai.has_image", true))
+	}
+
+	op, err := g.Client.Models.GenerateVideos(trackRetries(ctx), modelName(g.Client, g.model), req.Prompt, image, &config)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "video generation failed")
+		return VideoOperation{}, err
+	}
+
+	span.SetAttributes(attribute.String("ai.operation", op.Name))
+
+	return VideoOperation{Name: op.Name}, nil
+}
+
+// WaitForVideo polls the operation with exponential backoff until it's done or the context is cancelled,
+// then downloads the generated videos.
+func (g *VideoGenerator) WaitForVideo(ctx context.Context, op VideoOperation) (GenerateVideoResponse, error) {
+	ctx, span := g.tracer.Start(ctx, "google.wait_for_video",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("ai.operation", op.Name),
+		),
+	)
+	defer span.End()
+
+	log := logWithTrace(ctx, g.log).With("operation", op.Name)
+
+	operation := &genai.GenerateVideosOperation{Name: op.Name}
+	interval := g.pollInterval
+	var polls int
+	for {
+		var err error
+		operation, err = g.Client.Operations.GetVideosOperation(trackRetries(ctx), operation, nil)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "operation poll failed")
+			log.Info("Video generation operation poll failed", "poll", polls+1, "error", err)
+			return GenerateVideoResponse{}, fmt.Errorf("error polling operation: %w", err)
+		}
+		polls++
+
+		if operation.Done {
+			log.Debug("Video generation operation done", "poll_count", polls)
+			break
+		}
+
+		log.Debug("Video generation operation not done, polling again", "poll", polls, "interval", interval)
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			span.RecordError(ctx.Err())
+			span.SetStatus(codes.Error, "operation wait cancelled")
+			log.Info("Video generation operation wait cancelled", "error", ctx.Err())
+			return GenerateVideoResponse{}, ctx.Err()
+		case <-timer.C:
+		}
+
+		interval = min(interval*2, g.maxPollInterval)
+	}
+
+	span.SetAttributes(attribute.Int("ai.poll_count", polls))
+
+	if operation.Error != nil {
+		err := fmt.Errorf("video generation failed: %v", operation.Error["message"])
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "video generation failed")
+		return GenerateVideoResponse{}, err
+	}
+
+	var res GenerateVideoResponse
+	if operation.Response == nil {
+		return res, nil
+	}
+	res.RAIFilteredReasons = operation.Response.RAIMediaFilteredReasons
+
+	for _, video := range operation.Response.GeneratedVideos {
+		if video.Video == nil {
+			continue
+		}
+
+		// The Gemini API returns a URI to download, Vertex AI the video bytes directly
+		data := video.Video.VideoBytes
+		if len(data) == 0 {
+			var err error
+			data, err = g.Client.Files.Download(trackRetries(ctx), genai.NewDownloadURIFromGeneratedVideo(video), nil)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "video download failed")
+				return GenerateVideoResponse{}, fmt.Errorf("error downloading video: %w", err)
+			}
+		}
+
+		res.Videos = append(res.Videos, GeneratedVideo{
+			Data:     data,
+			MIMEType: video.Video.MIMEType,
+			URI:      video.Video.URI,
+		})
+	}
+
+	span.SetAttributes(
+		attribute.Int("ai.generated_video_count", len(res.Videos)),
+		attribute.Int("ai.filtered_video_count", int(operation.Response.RAIMediaFilteredCount)),
+	)
+
+	return res, nil
+}
@@ -0,0 +1,108 @@
+package google_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestVideoGenerator_GenerateVideo(t *testing.T) {
+	t.Run("can generate a video from a text and image prompt", func(t *testing.T) {
+		vg := newVideoGenerator(t)
+
+		res, err := vg.GenerateVideo(t.Context(), google.GenerateVideoRequest{
+			AspectRatio: "16:9",
+			Image:       &google.InputImage{Data: bytes.NewReader(image), MIMEType: "image/jpeg"},
+			Prompt:      "The logo slowly rotates.",
+		})
+		is.NotError(t, err)
+
+		is.Equal(t, 1, len(res.Videos))
+		is.Equal(t, "video/mp4", res.Videos[0].MIMEType)
+		is.True(t, len(res.Videos[0].Data) > 0, "should have video data")
+	})
+}
+
+func TestVideoGenerator_WaitForVideo(t *testing.T) {
+	t.Run("can continue waiting on an operation from another generator", func(t *testing.T) {
+		op, err := newVideoGenerator(t).StartVideoGeneration(t.Context(), google.GenerateVideoRequest{
+			Prompt: "A gopher waving hello.",
+		})
+		is.NotError(t, err)
+		is.True(t, op.Name != "", "should have operation name")
+
+		res, err := newVideoGenerator(t).WaitForVideo(t.Context(), google.VideoOperation{Name: op.Name})
+		is.NotError(t, err)
+
+		is.Equal(t, 1, len(res.Videos))
+		is.True(t, len(res.Videos[0].Data) > 0, "should have video data")
+	})
+
+	t.Run("polls the operation until it's done", func(t *testing.T) {
+		baseURL, polls := newVideoOperationServer(t, 3)
+		c := google.NewClient(google.NewClientOptions{BaseURL: baseURL, Key: "test"})
+		vg := c.NewVideoGenerator(google.NewVideoGeneratorOptions{PollInterval: time.Millisecond})
+
+		res, err := vg.WaitForVideo(t.Context(), google.VideoOperation{Name: "models/veo-3.0-generate-001/operations/test"})
+		is.NotError(t, err)
+
+		is.Equal(t, int64(3), polls.Load())
+		is.Equal(t, 1, len(res.Videos))
+		is.Equal(t, "video/mp4", res.Videos[0].MIMEType)
+		is.Equal(t, "Hi!", string(res.Videos[0].Data))
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		baseURL, polls := newVideoOperationServer(t, 0)
+		c := google.NewClient(google.NewClientOptions{BaseURL: baseURL, Key: "test"})
+		vg := c.NewVideoGenerator(google.NewVideoGeneratorOptions{PollInterval: time.Hour})
+
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		_, err := vg.WaitForVideo(ctx, google.VideoOperation{Name: "models/veo-3.0-generate-001/operations/test"})
+		is.True(t, errors.Is(err, context.DeadlineExceeded), "should be deadline exceeded")
+		is.Equal(t, int64(1), polls.Load())
+	})
+}
+
+// newVideoOperationServer is a stand-in for the Gemini API operations endpoint, with an operation that is done on
+// the given poll, or never if zero. It returns the base URL and the number of polls so far.
+func newVideoOperationServer(t *testing.T, donePoll int64) (string, *atomic.Int64) {
+	t.Helper()
+
+	var polls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/operations/test") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+
+		if poll := polls.Add(1); donePoll == 0 || poll < donePoll {
+			_, _ = w.Write([]byte(`{"name":"models/veo-3.0-generate-001/operations/test"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"models/veo-3.0-generate-001/operations/test","done":true,"response":{"generateVideoResponse":{"generatedSamples":[{"video":{"encodedVideo":"SGkh","encoding":"video/mp4"}}]}}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, &polls
+}
+
+func newVideoGenerator(t *testing.T) *google.VideoGenerator {
+	c := newClient(t)
+	return c.NewVideoGenerator(google.NewVideoGeneratorOptions{
+		Model: google.VideoGenerateModelVeo3Fast,
+	})
+}
@@ -0,0 +1,36 @@
+package google
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// PCMToWAV wraps raw little-endian PCM audio data in a WAV container.
+func PCMToWAV(pcm []byte, sampleRate, channels, bitsPerSample int) []byte {
+	blockAlign := channels * bitsPerSample / 8
+
+	var b bytes.Buffer
+	b.Grow(44 + len(pcm))
+
+	// RIFF header
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+len(pcm)))
+	b.WriteString("WAVE")
+
+	// Format chunk
+	b.WriteString("fmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(16))                    // Chunk size
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))                     // PCM format
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels))              // Channels
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate))            // Sample rate
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate*blockAlign)) // Byte rate
+	_ = binary.Write(&b, binary.LittleEndian, uint16(blockAlign))            // Block align
+	_ = binary.Write(&b, binary.LittleEndian, uint16(bitsPerSample))         // Bits per sample
+
+	// Data chunk
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(pcm)))
+	b.Write(pcm)
+
+	return b.Bytes()
+}
@@ -0,0 +1,32 @@
+package google_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"maragu.dev/is"
+
+	google "maragu.dev/gai-google"
+)
+
+func TestPCMToWAV(t *testing.T) {
+	t.Run("wraps PCM data in a WAV header", func(t *testing.T) {
+		pcm := []byte{1, 2, 3, 4}
+		wav := google.PCMToWAV(pcm, 24000, 1, 16)
+
+		is.Equal(t, 48, len(wav))
+		is.Equal(t, "RIFF", string(wav[0:4]))
+		is.Equal(t, uint32(40), binary.LittleEndian.Uint32(wav[4:8]))
+		is.Equal(t, "WAVE", string(wav[8:12]))
+		is.Equal(t, "fmt ", string(wav[12:16]))
+		is.Equal(t, uint16(1), binary.LittleEndian.Uint16(wav[22:24]))
+		is.Equal(t, uint32(24000), binary.LittleEndian.Uint32(wav[24:28]))
+		is.Equal(t, uint32(48000), binary.LittleEndian.Uint32(wav[28:32]))
+		is.Equal(t, uint16(2), binary.LittleEndian.Uint16(wav[32:34]))
+		is.Equal(t, uint16(16), binary.LittleEndian.Uint16(wav[34:36]))
+		is.Equal(t, "data", string(wav[36:40]))
+		is.Equal(t, uint32(4), binary.LittleEndian.Uint32(wav[40:44]))
+		is.True(t, bytes.Equal(pcm, wav[44:]), "should end with the PCM data")
+	})
+}